@@ -0,0 +1,62 @@
+package main
+
+import "math/big"
+
+// Clone returns a deep copy of a parsed value. Objects and arrays are copied
+// recursively, OrderedMaps keep their key order and comments, RawMessages
+// get their own backing array, *big.Ints are copied and a TypedValue's value
+// is cloned; other values are returned as is.
+func Clone(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			copied[key] = Clone(value)
+		}
+		return copied
+	case *OrderedMap:
+		if v == nil {
+			return v
+		}
+		copied := v.shallowCopy()
+		for i := range copied.pairs {
+			pair := &copied.pairs[i]
+			pair.Value = Clone(pair.Value)
+			pair.Comments = cloneComments(pair.Comments)
+			pair.ValueComments = cloneComments(pair.ValueComments)
+		}
+		copied.leading = cloneComments(copied.leading)
+		copied.trailing = cloneComments(copied.trailing)
+		copied.end = cloneComments(copied.end)
+		return copied
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, value := range v {
+			copied[i] = Clone(value)
+		}
+		return copied
+	case RawMessage:
+		if v == nil {
+			return v
+		}
+		return append(RawMessage{}, v...)
+	case *big.Int:
+		if v == nil {
+			return v
+		}
+		return new(big.Int).Set(v)
+	case TypedValue:
+		return TypedValue{JSONType: v.JSONType, Value: Clone(v.Value)}
+	}
+	return data
+}
+
+func cloneComments(comments []string) []string {
+	return append([]string(nil), comments...)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	input := `{"a":{"b":[1,{"c":"x"}]},"d":[[true]],"e":null}`
+	original := mustParse(t, input)
+	copied := Clone(original)
+	if !Equal(copied, original) {
+		t.Fatalf("Clone = %v, want %v", copied, original)
+	}
+
+	obj := copied.(map[string]interface{})
+	inner := obj["a"].(map[string]interface{})
+	arr := inner["b"].([]interface{})
+	arr[0] = "changed"
+	arr[1].(map[string]interface{})["c"] = "changed"
+	inner["new"] = 1
+	obj["d"].([]interface{})[0].([]interface{})[0] = false
+	delete(obj, "e")
+
+	if got := mustMarshal(t, original); got != input {
+		t.Errorf("changing the clone changed the original to %s", got)
+	}
+}
+
+func TestCloneOrderedAndRaw(t *testing.T) {
+	src := "{\n  // note\n  \"b\": [1],\n  \"a\": 2\n  // end\n}"
+	original, err := ParseWithOptions(src, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MarshalOptions{Indent: "  ", EmitComments: true}
+	want, _ := MarshalWithOptions(original, opts)
+	copied := Clone(original).(*OrderedMap)
+	if got, _ := MarshalWithOptions(copied, opts); got != want {
+		t.Errorf("cloned OrderedMap marshals as\n%s\nwant\n%s", got, want)
+	}
+	copied.Set("c", 3)
+	copied.Pairs()[0].Comments[0] = "// changed"
+	b, _ := copied.Get("b")
+	b.([]interface{})[0] = 9
+	if got, _ := MarshalWithOptions(original, opts); got != want {
+		t.Errorf("changing the clone changed the original to\n%s", got)
+	}
+
+	jsonc, err := ParseWithOptions(jsoncSample, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := MarshalWithOptions(Clone(jsonc), opts); got != jsoncOutput {
+		t.Errorf("cloned JSONC document marshals as\n%s\nwant\n%s", got, jsoncOutput)
+	}
+
+	raw := RawMessage(`[1]`)
+	rawCopy := Clone(raw).(RawMessage)
+	rawCopy[1] = '2'
+	if string(raw) != `[1]` {
+		t.Errorf("changing a cloned RawMessage changed the original to %s", raw)
+	}
+	for _, v := range []interface{}{nil, "s", int64(1), []interface{}(nil), map[string]interface{}(nil)} {
+		if got := Clone(v); !Equal(got, v) {
+			t.Errorf("Clone(%#v) = %#v", v, got)
+		}
+	}
+}
+
+func TestCloneBigIntAndTypedValue(t *testing.T) {
+	parsed, err := ParseWithOptions(`{"n":123456789012345678901234567890}`, Options{BigIntFallback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := parsed.(map[string]interface{})
+	copied := Clone(original).(map[string]interface{})
+	copied["n"].(*big.Int).SetInt64(1)
+	if got := original["n"].(*big.Int).String(); got != "123456789012345678901234567890" {
+		t.Errorf("changing the cloned *big.Int changed the original to %s", got)
+	}
+
+	tagged := TypedValue{JSONType: "number", Value: big.NewInt(7)}
+	copiedTag := Clone(tagged).(TypedValue)
+	copiedTag.Value.(*big.Int).SetInt64(8)
+	if tagged.Value.(*big.Int).Int64() != 7 || copiedTag.JSONType != "number" {
+		t.Errorf("cloning a TypedValue shared its value: %v, %v", tagged, copiedTag)
+	}
+	if got := Clone((*big.Int)(nil)); got != (*big.Int)(nil) {
+		t.Errorf("Clone of a nil *big.Int = %#v", got)
+	}
+}
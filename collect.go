@@ -0,0 +1,46 @@
+package main
+
+import "sort"
+
+// CollectStrings returns every string value in a parsed document in document
+// order. Members of a map[string]interface{} are visited in sorted key order.
+func CollectStrings(data interface{}) []string {
+	return collectStrings(data, false, []string{})
+}
+
+// CollectStringsWithKeys is like CollectStrings but also includes object keys,
+// each one immediately before the strings found in its value.
+func CollectStringsWithKeys(data interface{}) []string {
+	return collectStrings(data, true, []string{})
+}
+
+func collectStrings(data interface{}, keys bool, out []string) []string {
+	switch v := data.(type) {
+	case string:
+		out = append(out, v)
+	case []interface{}:
+		for _, elem := range v {
+			out = collectStrings(elem, keys, out)
+		}
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if keys {
+				out = append(out, name)
+			}
+			out = collectStrings(v[name], keys, out)
+		}
+	case *OrderedMap:
+		for _, pair := range v.Pairs() {
+			if keys {
+				out = append(out, pair.Key)
+			}
+			out = collectStrings(pair.Value, keys, out)
+		}
+	}
+	return out
+}
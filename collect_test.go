@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollectStrings(t *testing.T) {
+	data := mustParse(t, sampleDocument)
+	ordered, err := ParseWithOptions(sampleDocument, Options{OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  []string
+		want string
+	}{
+		{"map", CollectStrings(data), "South Asia|Asia|Kathmandu|Lalitpur|nepal"},
+		{"map with keys", CollectStringsWithKeys(data), "a/b~c|address|Location|South Asia|continent|Asia|age|country|districts|Kathmandu|Lalitpur|name|nepal"},
+		{"ordered", CollectStrings(ordered), "nepal|Kathmandu|Lalitpur|Asia|South Asia"},
+		{"ordered with keys", CollectStringsWithKeys(ordered), "name|nepal|age|country|districts|Kathmandu|Lalitpur|address|continent|Asia|Location|South Asia|a/b~c"},
+		{"scalar", CollectStrings("x"), "x"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.got, "|"); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := CollectStrings([]interface{}{int64(1), nil}); got == nil || len(got) != 0 {
+		t.Errorf("CollectStrings without strings = %#v, want an empty slice", got)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import "strings"
+
+// Compact removes insignificant whitespace from a JSON document. Tokens are
+// copied exactly as written, so string escapes and number formatting are
+// preserved.
+func Compact(input string) (string, error) {
+	if err := ValidateError(input); err != nil {
+		return "", err
+	}
+
+	lexer := NewLexer(input)
+	var sb strings.Builder
+	sb.Grow(len(input))
+	for {
+		token, err := lexer.nextToken()
+		if err != nil {
+			return "", err
+		}
+		if token.Type == TokenEOF {
+			return sb.String(), nil
+		}
+		sb.WriteString(input[token.Offset : lexer.base+lexer.offset])
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	got, err := Compact(sampleDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"nepal","age":0,"country":true,"districts":["Kathmandu","Lalitpur"],"address":{"continent":"Asia","Location":"South Asia"},"a/b~c":1}`
+	if got != want {
+		t.Errorf("Compact = %s, want %s", got, want)
+	}
+	if len(got) >= len(sampleDocument) {
+		t.Errorf("Compact did not shrink the document: %d >= %d bytes", len(got), len(sampleDocument))
+	}
+	original, _ := Parse(sampleDocument)
+	compacted, err := Parse(got)
+	if err != nil || !Equal(original, compacted) {
+		t.Errorf("compacted document parses as %v, %v", compacted, err)
+	}
+}
+
+func TestCompactPreservesTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"[ \"a b\\n\\u00e9\" ,\t1.50E+3 , -0 ]", `["a b\n\u00e9",1.50E+3,-0]`},
+		{"\xEF\xBB\xBF {\r\n} ", `{}`},
+		{` "  inner  spaces  " `, `"  inner  spaces  "`},
+	}
+	for _, tt := range tests {
+		if got, err := Compact(tt.input); err != nil || got != tt.want {
+			t.Errorf("Compact(%q) = %s, %v; want %s", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{`[1,]`, `{"a" 1}`, `[1] 2`} {
+		if _, err := Compact(input); err == nil || !strings.Contains(err.Error(), "parse error") {
+			t.Errorf("Compact(%s) error = %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+// AsFloatSlice converts a parsed array whose elements are all numbers to a
+// []float64. It returns false if v is not an array or holds anything else.
+func AsFloatSlice(v interface{}) ([]float64, bool) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]float64, len(arr))
+	for i, elem := range arr {
+		switch n := elem.(type) {
+		case int64:
+			result[i] = float64(n)
+		case float64:
+			result[i] = n
+		case Number:
+			f, err := n.Float64()
+			if err != nil {
+				return nil, false
+			}
+			result[i] = f
+		case Decimal:
+			f, err := n.Float64()
+			if err != nil {
+				return nil, false
+			}
+			result[i] = f
+		default:
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+// AsStringSlice converts a parsed array whose elements are all strings to a
+// []string. It returns false if v is not an array or holds anything else.
+func AsStringSlice(v interface{}) ([]string, bool) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, len(arr))
+	for i, elem := range arr {
+		s, ok := elem.(string)
+		if !ok {
+			return nil, false
+		}
+		result[i] = s
+	}
+	return result, true
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAsFloatSlice(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		want  []float64
+		ok    bool
+	}{
+		{`[1, 2.5, -3e2]`, Options{}, []float64{1, 2.5, -300}, true},
+		{`[]`, Options{}, []float64{}, true},
+		{`[1, 2.5]`, Options{UseNumber: true}, []float64{1, 2.5}, true},
+		{`[1, 2.5]`, Options{UseDecimalStrings: true}, []float64{1, 2.5}, true},
+		{`[1, "2"]`, Options{}, nil, false},
+		{`[1, null]`, Options{}, nil, false},
+		{`[[1]]`, Options{}, nil, false},
+		{`{"a": 1}`, Options{}, nil, false},
+		{`1`, Options{}, nil, false},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := AsFloatSlice(v)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AsFloatSlice(%s) = %v, %t; want %v, %t", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAsStringSlice(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+		ok    bool
+	}{
+		{`["a", "", "é"]`, []string{"a", "", "é"}, true},
+		{`[]`, []string{}, true},
+		{`["a", 1]`, nil, false},
+		{`["a", null]`, nil, false},
+		{`[["a"]]`, nil, false},
+		{`"a"`, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := AsStringSlice(mustParse(t, tt.input))
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AsStringSlice(%s) = %v, %t; want %v, %t", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
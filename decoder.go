@@ -0,0 +1,236 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// Decoder reads a sequence of JSON values from a stream, one per call to
+// Decode. Values may be separated by any amount of whitespace.
+type Decoder struct {
+	r      io.Reader
+	parser *Parser
+	err    error
+	// more is set once a top-level value has been read. The token after it
+	// is only scanned by the next call, so a complete value is returned
+	// without waiting for more input.
+	more bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode parses the next value from the stream. It returns io.EOF once the
+// input is exhausted.
+func (d *Decoder) Decode() (interface{}, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	if d.parser.token.Type == TokenEOF {
+		return nil, io.EOF
+	}
+
+	value, err := d.parser.parseValue()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	d.more = true
+	return value, nil
+}
+
+// Skip consumes the next value in the stream without decoding it. It returns
+// io.EOF once the input is exhausted.
+func (d *Decoder) Skip() error {
+	if err := d.init(); err != nil {
+		return err
+	}
+	if d.parser.token.Type == TokenEOF {
+		return io.EOF
+	}
+	if err := d.parser.SkipValue(); err != nil {
+		d.err = err
+		return err
+	}
+	d.more = true
+	return nil
+}
+
+func (d *Decoder) init() error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.parser == nil {
+		parser, err := NewParser(NewLexerReader(d.r))
+		if err != nil {
+			d.err = err
+			return err
+		}
+		parser.valueOnly = true
+		d.parser = parser
+	}
+	if d.more {
+		d.more = false
+		d.parser.valueOnly = false
+		err := d.parser.nextToken()
+		d.parser.valueOnly = true
+		if err != nil {
+			d.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// ArrayIterator yields the elements of an array one at a time. Next and Skip
+// return false once the closing bracket has been read; Skip consumes an
+// element without decoding it.
+type ArrayIterator interface {
+	Next() (interface{}, bool, error)
+	Skip() (bool, error)
+}
+
+type arrayIterator struct {
+	d     *Decoder
+	count int
+	done  bool
+}
+
+// Array starts reading the next value in the stream, which must be an array,
+// and returns an iterator over its elements. Only the current element is held
+// in memory. Once the iterator is exhausted, Decode continues with the value
+// after the array.
+func (d *Decoder) Array() (ArrayIterator, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	p := d.parser
+	if p.token.Type != TokenLeftBracket {
+		return nil, p.errorf("expected '[' to start array, found %s", describeToken(p.token))
+	}
+	if err := p.enter(); err != nil {
+		d.err = err
+		return nil, err
+	}
+	p.path = append(p.path, pathSegment{isIndex: true})
+	if err := p.nextToken(); err != nil {
+		d.err = err
+		return nil, err
+	}
+	return &arrayIterator{d: d}, nil
+}
+
+func (it *arrayIterator) Next() (interface{}, bool, error) {
+	var value interface{}
+	ok, err := it.step(func(p *Parser) (err error) {
+		value, err = p.parseValue()
+		return err
+	})
+	return value, ok, err
+}
+
+func (it *arrayIterator) Skip() (bool, error) {
+	return it.step((*Parser).SkipValue)
+}
+
+// step moves to the next element and hands it to read, recording any error
+// on the decoder.
+func (it *arrayIterator) step(read func(p *Parser) error) (bool, error) {
+	if it.done {
+		return false, nil
+	}
+	if it.d.err != nil {
+		return false, it.d.err
+	}
+
+	ok, err := it.next(read)
+	if err != nil {
+		it.d.err = err
+	}
+	return ok, err
+}
+
+func (it *arrayIterator) next(read func(p *Parser) error) (bool, error) {
+	p := it.d.parser
+	if it.count > 0 {
+		if err := p.arraySeparator(); err != nil {
+			return false, err
+		}
+	}
+	if p.token.Type == TokenRightBracket {
+		it.done = true
+		p.path = p.path[:len(p.path)-1]
+		p.leave()
+		it.d.more = true
+		return false, p.nextToken()
+	}
+
+	if err := read(p); err != nil {
+		return false, err
+	}
+	it.count++
+	return true, nil
+}
+
+// ParseLines parses newline-delimited JSON, one value per line. Blank lines
+// are skipped, and errors report the line of the input they occurred on.
+func ParseLines(input string) ([]interface{}, error) {
+	values := []interface{}{}
+	offset := 0
+	for i, line := range strings.Split(input, "\n") {
+		lineStart := offset
+		offset += len(line) + 1
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		value, err := Parse(line)
+		if err != nil {
+			if perr, ok := err.(*ParseError); ok {
+				perr.Line = i + 1
+				perr.Offset += lineStart
+			}
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// ParseStream parses a sequence of top-level values separated by any amount
+// of whitespace, such as {"a":1} {"b":2}. An input holding only whitespace
+// yields no values.
+func ParseStream(input string) ([]interface{}, error) {
+	parser, err := NewParser(NewLexer(input))
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for parser.token.Type != TokenEOF {
+		value, err := parser.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// SplitValues splits a sequence of top-level values, as accepted by
+// ParseStream, into the source text of each value. The values are checked
+// for syntax but not decoded.
+func SplitValues(input string) ([]string, error) {
+	parser, err := NewParser(NewLexer(input))
+	if err != nil {
+		return nil, err
+	}
+	spans := []string{}
+	for parser.token.Type != TokenEOF {
+		start := parser.token.Offset
+		if err := parser.SkipValue(); err != nil {
+			return nil, err
+		}
+		spans = append(spans, input[start:parser.prevEnd])
+	}
+	return spans, nil
+}
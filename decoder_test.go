@@ -0,0 +1,356 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoderStopsAfterValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a":1} x`, `{"a":1}`},
+		{`[1,2] @`, `[1,2]`},
+		{`"s" }`, `"s"`},
+	}
+	for _, tt := range tests {
+		d := NewDecoder(strings.NewReader(tt.input))
+		v, err := d.Decode()
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.input, err)
+			continue
+		}
+		if got, _ := Marshal(v); got != tt.want {
+			t.Errorf("Decode(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+		if _, err := d.Decode(); err == nil || err == io.EOF {
+			t.Errorf("second Decode(%q) = %v, want a parse error", tt.input, err)
+		}
+	}
+}
+
+// decodeWithin runs decode against a pipe that has been sent input but is not
+// yet closed, and reports whether it returned within the timeout.
+func decodeWithin(t *testing.T, input string, decode func(d *Decoder)) {
+	t.Helper()
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte(input))
+
+	done := make(chan struct{})
+	go func() {
+		decode(NewDecoder(r))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("decoding %q waited for more input", input)
+	}
+}
+
+func TestDecoderDoesNotWaitForNextValue(t *testing.T) {
+	for _, input := range []string{`{"a":1}`, `[1,2]`, `"s"`} {
+		decodeWithin(t, input, func(d *Decoder) {
+			if _, err := d.Decode(); err != nil {
+				t.Errorf("Decode(%q): %v", input, err)
+			}
+		})
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	d := NewDecoder(strings.NewReader(` 1 [2,3] "s"  `))
+	var got []string
+	for {
+		v, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, _ := Marshal(v)
+		got = append(got, s)
+	}
+	if strings.Join(got, " ") != `1 [2,3] "s"` {
+		t.Errorf("decoded %v", got)
+	}
+}
+
+func TestDecoderObjects(t *testing.T) {
+	d := NewDecoder(strings.NewReader("{\"a\":1}{\"b\":[2]}\n {\"c\":{}}\n"))
+	for _, want := range []string{`{"a":1}`, `{"b":[2]}`, `{"c":{}}`} {
+		v, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := Marshal(v); got != want {
+			t.Errorf("Decode() = %s, want %s", got, want)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := d.Decode(); err != io.EOF {
+			t.Errorf("Decode() at end = %v, want io.EOF", err)
+		}
+	}
+	if _, err := NewDecoder(strings.NewReader("  ")).Decode(); err != io.EOF {
+		t.Errorf("Decode() of empty input = %v, want io.EOF", err)
+	}
+}
+
+func TestArrayIterator(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`[1,[2],3] "after" x`))
+	it, err := d.Array()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for {
+		v, ok, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		s, _ := Marshal(v)
+		got = append(got, s)
+	}
+	if strings.Join(got, " ") != `1 [2] 3` {
+		t.Errorf("elements %v", got)
+	}
+	if v, err := d.Decode(); err != nil || v != "after" {
+		t.Errorf("Decode after array = %v, %v", v, err)
+	}
+}
+
+func TestArrayIteratorDoesNotWaitAfterArray(t *testing.T) {
+	decodeWithin(t, `[1,2]`, func(d *Decoder) {
+		it, err := d.Array()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for {
+			_, ok, err := it.Next()
+			if err != nil {
+				t.Error(err)
+			}
+			if !ok || err != nil {
+				return
+			}
+		}
+	})
+}
+
+func TestParseLines(t *testing.T) {
+	values, err := ParseLines("{\"a\":1}\n[2,3]\n\n  \n\"s\"\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Marshal(values); got != `[{"a":1},[2,3],"s"]` {
+		t.Errorf("ParseLines = %s", got)
+	}
+
+	tests := []struct {
+		input     string
+		line, col int
+		offset    int
+	}{
+		{"{\"a\":1}\n[1 x]\n[3]", 2, 4, 11},
+		{"1\n\n{\"b\":}", 3, 6, 8},
+	}
+	for _, tt := range tests {
+		_, err := ParseLines(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseLines(%q) error = %v, want *ParseError", tt.input, err)
+			continue
+		}
+		if perr.Line != tt.line || perr.Col != tt.col || perr.Offset != tt.offset {
+			t.Errorf("ParseLines(%q) error at %d:%d offset %d, want %d:%d offset %d",
+				tt.input, perr.Line, perr.Col, perr.Offset, tt.line, tt.col, tt.offset)
+		}
+	}
+}
+
+// countingArray generates the array [0,1,...,n-1] as it is read, so that the
+// whole document is never held in memory.
+type countingArray struct {
+	n, next int
+	buf     []byte
+}
+
+func (r *countingArray) Read(p []byte) (int, error) {
+	for len(r.buf) < len(p) && r.next <= r.n {
+		switch {
+		case r.next == r.n:
+			r.buf = append(r.buf, ']')
+		case r.next == 0:
+			r.buf = append(r.buf, '[', '0')
+		default:
+			r.buf = append(r.buf, ',')
+			r.buf = strconv.AppendInt(r.buf, int64(r.next), 10)
+		}
+		r.next++
+	}
+	if len(r.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+	return n, nil
+}
+
+func TestArrayIteratorLargeArray(t *testing.T) {
+	const n = 200000
+	d := NewDecoder(&countingArray{n: n})
+	it, err := d.Array()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for want := int64(0); ; want++ {
+		v, ok, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			if want != n {
+				t.Errorf("iterated %d elements, want %d", want, n)
+			}
+			break
+		}
+		if v != want {
+			t.Fatalf("element %d = %v", want, v)
+		}
+		if size := cap(d.parser.lexer.input); size > 4*readChunkSize {
+			t.Fatalf("lexer buffer grew to %d bytes at element %d", size, want)
+		}
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("Decode after the array = %v, want io.EOF", err)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a":1} {"b":2}`, `[{"a":1},{"b":2}]`},
+		{"{\"a\":1}\n{\"b\":2}", `[{"a":1},{"b":2}]`},
+		{"{\"a\":1}{\"b\":2}\t\r\n  ", `[{"a":1},{"b":2}]`},
+		{`1 "s" [true] null`, `[1,"s",[true],null]`},
+		{`  `, `[]`},
+		{``, `[]`},
+	}
+	for _, tt := range tests {
+		values, err := ParseStream(tt.input)
+		if err != nil {
+			t.Errorf("ParseStream(%q): %v", tt.input, err)
+		} else if got := mustMarshal(t, values); got != tt.want {
+			t.Errorf("ParseStream(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	for _, input := range []string{`{"a":1} {`, `1 , 2`, `[1] ]`} {
+		if _, err := ParseStream(input); err == nil {
+			t.Errorf("ParseStream(%q) succeeded", input)
+		}
+	}
+}
+
+func TestSkipThenDecode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`[{"big":[1,2,{"x":"]"}]}, {"id":2}]`, `{"id":2}`},
+		{`["skip", "keep"]`, `"keep"`},
+		{`[[[]], 3]`, `3`},
+		{`[null, [true]]`, `[true]`},
+	}
+	for _, tt := range tests {
+		it, err := NewDecoder(strings.NewReader(tt.input)).Array()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := it.Skip(); !ok || err != nil {
+			t.Errorf("Skip in %s = %t, %v", tt.input, ok, err)
+			continue
+		}
+		v, ok, err := it.Next()
+		if !ok || err != nil {
+			t.Errorf("Next after Skip in %s = %t, %v", tt.input, ok, err)
+			continue
+		}
+		if got := mustMarshal(t, v); got != tt.want {
+			t.Errorf("second element of %s = %s, want %s", tt.input, got, tt.want)
+		}
+		if _, ok, err := it.Next(); ok || err != nil {
+			t.Errorf("Next at the end of %s = %t, %v", tt.input, ok, err)
+		}
+	}
+
+	d := NewDecoder(strings.NewReader(`{"skipped":[1,2]} {"kept":true} 3`))
+	if err := d.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.Decode(); err != nil || mustMarshal(t, v) != `{"kept":true}` {
+		t.Errorf("Decode after Skip = %v, %v", v, err)
+	}
+	if err := d.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Skip(); err != io.EOF {
+		t.Errorf("Skip at the end of the stream = %v, want io.EOF", err)
+	}
+
+	d = NewDecoder(strings.NewReader(`[1,}`))
+	if err := d.Skip(); err == nil {
+		t.Error("Skip of a malformed value succeeded")
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{`{"a":1}[2,3]"x"`, []string{`{"a":1}`, `[2,3]`, `"x"`}},
+		{" {\"a\": [1, 2]}\n\"s\"  3 ", []string{`{"a": [1, 2]}`, `"s"`, `3`}},
+		{`1 2`, []string{`1`, `2`}},
+		{`true null`, []string{`true`, `null`}},
+		{`"é" {"k":"}"}`, []string{`"é"`, `{"k":"}"}`}},
+		{"", []string{}},
+		{" \n ", []string{}},
+	}
+	for _, tt := range tests {
+		spans, err := SplitValues(tt.input)
+		if err != nil {
+			t.Errorf("SplitValues(%q): %v", tt.input, err)
+			continue
+		}
+		if len(spans) != len(tt.want) {
+			t.Errorf("SplitValues(%q) = %q, want %q", tt.input, spans, tt.want)
+			continue
+		}
+		for i, span := range spans {
+			if span != tt.want[i] {
+				t.Errorf("SplitValues(%q)[%d] = %q, want %q", tt.input, i, span, tt.want[i])
+			}
+			if _, err := Parse(span); err != nil {
+				t.Errorf("span %q does not re-parse: %v", span, err)
+			}
+		}
+	}
+	for _, input := range []string{`[1,]`, `{"a":1} [`, `1 }`, `truenull`} {
+		if spans, err := SplitValues(input); err == nil {
+			t.Errorf("SplitValues(%q) = %q, want an error", input, spans)
+		}
+	}
+}
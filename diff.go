@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Change is one difference found by Diff. Path is a JSON Pointer, Op is
+// "add", "remove" or "replace", and Old and New hold the values on either
+// side; Old is nil for an add and New is nil for a remove.
+type Change struct {
+	Path string
+	Op   string
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff lists the changes that turn a into b. Objects are compared member by
+// member in sorted key order and arrays element by element by index, with
+// elements past the end of the shorter array reported as added or removed.
+// Values are compared with Equal.
+func Diff(a, b interface{}) []Change {
+	return diffValues("", a, b, []Change{})
+}
+
+func diffValues(path string, a, b interface{}, changes []Change) []Change {
+	if x, ok := objectMembers(a); ok {
+		if y, ok := objectMembers(b); ok {
+			return diffObjects(path, x, y, changes)
+		}
+	}
+	if x, ok := a.([]interface{}); ok {
+		if y, ok := b.([]interface{}); ok {
+			return diffArrays(path, x, y, changes)
+		}
+	}
+	if !Equal(a, b) {
+		changes = append(changes, Change{Path: path, Op: "replace", Old: a, New: b})
+	}
+	return changes
+}
+
+func diffObjects(path string, a, b map[string]interface{}, changes []Change) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := path + "/" + pointerEscaper.Replace(key)
+		x, inA := a[key]
+		y, inB := b[key]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Path: child, Op: "add", New: y})
+		case !inB:
+			changes = append(changes, Change{Path: child, Op: "remove", Old: x})
+		default:
+			changes = diffValues(child, x, y, changes)
+		}
+	}
+	return changes
+}
+
+func diffArrays(path string, a, b []interface{}, changes []Change) []Change {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		child := path + "/" + strconv.Itoa(i)
+		switch {
+		case i >= len(a):
+			changes = append(changes, Change{Path: child, Op: "add", New: b[i]})
+		case i >= len(b):
+			changes = append(changes, Change{Path: child, Op: "remove", Old: a[i]})
+		default:
+			changes = diffValues(child, a[i], b[i], changes)
+		}
+	}
+	return changes
+}
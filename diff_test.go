@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Change
+	}{
+		{
+			sampleDocument,
+			`{"name":"nepal","age":0,"country":true,"districts":["Kathmandu","Bhaktapur"],"address":{"continent":"Asia","Location":"Himalaya"},"a/b~c":1}`,
+			[]Change{
+				{Path: "/address/Location", Op: "replace", Old: "South Asia", New: "Himalaya"},
+				{Path: "/districts/1", Op: "replace", Old: "Lalitpur", New: "Bhaktapur"},
+			},
+		},
+		{
+			`{"a":1,"b":{"c":[1,2]},"x~y":true}`,
+			`{"b":{"c":[1,2,3]},"d":null,"x~y":false}`,
+			[]Change{
+				{Path: "/a", Op: "remove", Old: 1.0},
+				{Path: "/b/c/2", Op: "add", New: 3.0},
+				{Path: "/d", Op: "add", New: nil},
+				{Path: "/x~0y", Op: "replace", Old: true, New: false},
+			},
+		},
+		{`[1,2,3]`, `[1]`, []Change{
+			{Path: "/1", Op: "remove", Old: 2.0},
+			{Path: "/2", Op: "remove", Old: 3.0},
+		}},
+		{`{"a":[1]}`, `{"a":{"0":1}}`, []Change{
+			{Path: "/a", Op: "replace", Old: []interface{}{1.0}, New: map[string]interface{}{"0": 1.0}},
+		}},
+		{`1`, `1.0`, []Change{}},
+		{`"s"`, `null`, []Change{{Path: "", Op: "replace", Old: "s", New: nil}}},
+		{sampleDocument, sampleDocument, []Change{}},
+	}
+	for _, tt := range tests {
+		got := Diff(mustParse(t, tt.a), mustParse(t, tt.b))
+		if len(got) != len(tt.want) {
+			t.Errorf("Diff(%s, %s) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+			continue
+		}
+		for i, w := range tt.want {
+			c := got[i]
+			if c.Path != w.Path || c.Op != w.Op || !Equal(c.Old, w.Old) || !Equal(c.New, w.New) {
+				t.Errorf("Diff(%s, %s)[%d] = %+v, want %+v", tt.a, tt.b, i, c, w)
+			}
+		}
+	}
+
+	ordered, err := ParseWithOptions(`{"a":1,"b":2}`, Options{OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Diff(ordered, mustParse(t, `{"b":2,"a":1}`)); len(got) != 0 {
+		t.Errorf("Diff between an ordered and a plain object = %+v", got)
+	}
+}
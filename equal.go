@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Equal reports whether two parsed values are the same JSON value. Objects
+// compare by their members regardless of key order or whether they are maps
+// or OrderedMaps, and numbers compare by value whatever their Go type, so
+// int64(1), float64(1) and Number("1.0") are all equal.
+func Equal(a, b interface{}) bool {
+	if x, ok := numericValue(a); ok {
+		y, ok := numericValue(b)
+		return ok && numbersEqual(x, y)
+	}
+	if x, ok := objectMembers(a); ok {
+		y, ok := objectMembers(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		other, ok := b.(bool)
+		return ok && a == other
+	case string:
+		other, ok := b.(string)
+		return ok && a == other
+	case []interface{}:
+		other, ok := b.([]interface{})
+		if !ok || len(a) != len(other) {
+			return false
+		}
+		for i := range a {
+			if !Equal(a[i], other[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func objectMembers(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case *OrderedMap:
+		members := make(map[string]interface{}, v.Len())
+		for _, pair := range v.Pairs() {
+			members[pair.Key] = pair.Value
+		}
+		return members, true
+	}
+	return nil, false
+}
+
+// numericValue normalizes the number representations the parser can produce
+// to either int64 or float64.
+func numericValue(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case int64, float64, *big.Int:
+		return n, true
+	case Number:
+		value, err := parseNumber(string(n))
+		return value, err == nil
+	case Decimal:
+		value, err := parseNumber(string(n))
+		return value, err == nil
+	}
+	return nil, false
+}
+
+func numbersEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return x == y
+		case float64:
+			return floatEqualsInt(y, x)
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return floatEqualsInt(x, y)
+		case float64:
+			return x == y
+		}
+	case *big.Int:
+		switch y := b.(type) {
+		case *big.Int:
+			return x.Cmp(y) == 0
+		case int64:
+			return x.IsInt64() && x.Int64() == y
+		case float64:
+			return !math.IsNaN(y) && new(big.Float).SetInt(x).Cmp(big.NewFloat(y)) == 0
+		}
+	}
+	if _, ok := b.(*big.Int); ok {
+		return numbersEqual(b, a)
+	}
+	return false
+}
+
+func floatEqualsInt(f float64, i int64) bool {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return false
+	}
+	return int64(f) == i
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	ordered := NewOrderedMap()
+	ordered.Set("b", []interface{}{int64(1)})
+	ordered.Set("a", "x")
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{nil, nil, true},
+		{nil, false, false},
+		{true, true, true},
+		{"x", "x", true},
+		{"x", "y", false},
+		{int64(1), 1.0, true},
+		{1.0, int64(1), true},
+		{int64(1), 1.5, false},
+		{Number("1.0"), int64(1), true},
+		{Decimal("2"), Number("2e0"), true},
+		{big.NewInt(3), int64(3), true},
+		{big.NewInt(3), 3.0, true},
+		{int64(4), big.NewInt(3), false},
+		{math.NaN(), math.NaN(), false},
+		{int64(1), "1", false},
+		{[]interface{}{int64(1), "a"}, []interface{}{1.0, "a"}, true},
+		{[]interface{}{int64(1)}, []interface{}{int64(1), int64(2)}, false},
+		{[]interface{}{}, map[string]interface{}{}, false},
+		{map[string]interface{}{"a": "x", "b": []interface{}{1.0}}, ordered, true},
+		{ordered, map[string]interface{}{"a": "x"}, false},
+		{map[string]interface{}{"a": nil}, map[string]interface{}{"b": nil}, false},
+		{
+			map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{int64(1), map[string]interface{}{"c": true}}}},
+			map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1.0, map[string]interface{}{"c": true}}}},
+			true,
+		},
+		{
+			map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{int64(1), map[string]interface{}{"c": true}}}},
+			map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1.0, map[string]interface{}{"c": false}}}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
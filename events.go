@@ -0,0 +1,26 @@
+package main
+
+// EventHandler receives callbacks from ParseEvents as the document is read.
+// Returning an error from any method stops parsing and is returned as is.
+type EventHandler interface {
+	OnObjectStart() error
+	OnObjectEnd() error
+	OnArrayStart() error
+	OnArrayEnd() error
+	OnKey(key string) error
+	OnValue(value interface{}) error
+}
+
+// ParseEvents parses input and reports its structure to handler instead of
+// building a value. Scalars are passed to OnValue converted the same way
+// Parse converts them.
+func ParseEvents(input string, handler EventHandler) error {
+	parser, err := NewParser(NewLexer(input))
+	if err != nil {
+		return err
+	}
+	parser.discard = true
+	parser.handler = handler
+	_, err = parser.parseDocument()
+	return err
+}
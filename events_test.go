@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// eventRecorder records each event as a short string. failOn, if set, makes
+// the event with that text return errStop.
+type eventRecorder struct {
+	events []string
+	failOn string
+}
+
+var errStop = errors.New("stop")
+
+func (r *eventRecorder) record(event string) error {
+	r.events = append(r.events, event)
+	if event == r.failOn {
+		return errStop
+	}
+	return nil
+}
+
+func (r *eventRecorder) OnObjectStart() error { return r.record("{") }
+func (r *eventRecorder) OnObjectEnd() error   { return r.record("}") }
+func (r *eventRecorder) OnArrayStart() error  { return r.record("[") }
+func (r *eventRecorder) OnArrayEnd() error    { return r.record("]") }
+func (r *eventRecorder) OnKey(key string) error {
+	return r.record("key " + key)
+}
+func (r *eventRecorder) OnValue(value interface{}) error {
+	return r.record(fmt.Sprintf("%T %v", value, value))
+}
+
+func TestParseEvents(t *testing.T) {
+	var r eventRecorder
+	if err := ParseEvents(sampleDocument, &r); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"{",
+		"key name", "string nepal",
+		"key age", "int64 0",
+		"key country", "bool true",
+		"key districts", "[", "string Kathmandu", "string Lalitpur", "]",
+		"key address", "{", "key continent", "string Asia", "key Location", "string South Asia", "}",
+		"key a/b~c", "int64 1",
+		"}",
+	}
+	if got := strings.Join(r.events, "|"); got != strings.Join(want, "|") {
+		t.Errorf("events:\n%s\nwant:\n%s", got, strings.Join(want, "|"))
+	}
+}
+
+func TestParseEventsScalar(t *testing.T) {
+	var r eventRecorder
+	if err := ParseEvents(`null`, &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.events) != 1 || r.events[0] != "<nil> <nil>" {
+		t.Errorf("events = %q", r.events)
+	}
+}
+
+func TestParseEventsErrors(t *testing.T) {
+	r := eventRecorder{failOn: "key b"}
+	if err := ParseEvents(`{"a":1,"b":2,"c":3}`, &r); err != errStop {
+		t.Errorf("ParseEvents = %v, want the handler's error", err)
+	}
+	if got := strings.Join(r.events, "|"); got != "{|key a|int64 1|key b" {
+		t.Errorf("events before stopping = %s", got)
+	}
+
+	r = eventRecorder{}
+	err := ParseEvents(`[1,{"a":}]`, &r)
+	if err == nil || !strings.Contains(err.Error(), `missing value for key "a"`) {
+		t.Errorf("ParseEvents error = %v", err)
+	}
+	if got := strings.Join(r.events, "|"); got != "[|int64 1|{|key a" {
+		t.Errorf("events before the error = %s", got)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+// ParseFields parses a document whose top-level value is an object and
+// returns only the members named in keys. The values of other members are
+// checked for syntax but skipped without being decoded. Keys the object does
+// not have are absent from the result.
+func ParseFields(input string, keys []string) (map[string]interface{}, error) {
+	p, err := NewParser(newStringLexer(input))
+	if err != nil {
+		return nil, err
+	}
+	if p.token.Type != TokenLeftBrace {
+		return nil, p.errorf("expected '{' to start object, found %s", describeToken(p.token))
+	}
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	c, err := p.openObject()
+	if err != nil {
+		return nil, err
+	}
+	for p.token.Type != TokenRightBrace {
+		if err := p.readKey(c); err != nil {
+			return nil, err
+		}
+		if wanted[c.key] {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			p.storeMember(c, value)
+		} else {
+			if err := p.SkipValue(); err != nil {
+				return nil, err
+			}
+			p.path = p.path[:len(p.path)-1]
+		}
+		if err := p.objectSeparator(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.closeObject(c); err != nil {
+		return nil, err
+	}
+	if p.token.Type != TokenEOF {
+		return nil, p.errorf("unexpected trailing content after top-level value")
+	}
+	return c.obj, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want map[string]interface{}
+	}{
+		{[]string{"name", "address"}, map[string]interface{}{
+			"name":    "nepal",
+			"address": map[string]interface{}{"continent": "Asia", "Location": "South Asia"},
+		}},
+		{[]string{"a/b~c", "zip"}, map[string]interface{}{"a/b~c": int64(1)}},
+		{[]string{"continent"}, map[string]interface{}{}},
+		{nil, map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseFields(sampleDocument, tt.keys)
+		if err != nil {
+			t.Errorf("ParseFields(%q): %v", tt.keys, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFields(%q) = %v, want %v", tt.keys, got, tt.want)
+		}
+	}
+
+	input := `{"a":"x\ny","skip":"\t","b":"\u00e9","c":"plain","d":["\u0041",{"e":"f"}]}`
+	got, err := ParseFields(input, []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := mustMarshal(t, got); s != `{"a":"x\ny","b":"é","c":"plain","d":["A",{"e":"f"}]}` {
+		t.Errorf("ParseFields with escapes = %s", s)
+	}
+
+	errorTests := []struct {
+		input string
+		err   string
+	}{
+		{`[1]`, "parse error at line 1, column 1: expected '{' to start object, found '['"},
+		// Skipped members are still checked for syntax.
+		{`{"skip":[1,2,], "a":1}`, "parse error at $.skip[2], line 1, column 14: trailing comma before ']'"},
+		{`{"a":1,"b":tru}`, `parse error at $.b, line 1, column 12: invalid literal "tru"`},
+		{`{"a":1} 2`, "parse error at line 1, column 9: unexpected trailing content after top-level value"},
+		{`{"a":1`, "parse error at line 1, column 7: unexpected end of input: missing '}' to close object"},
+	}
+	for _, tt := range errorTests {
+		_, err := ParseFields(tt.input, []string{"a"})
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("ParseFields(%s) error = %v, want %s", tt.input, err, tt.err)
+		}
+	}
+}
+
+// wideObject has n members, each holding a small nested document.
+func wideObject(n int) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `"field%d":{"id":%d,"tags":["a","b","c"],"nested":{"ok":true,"score":%d.5}}`, i, i, i)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func BenchmarkParseFields(b *testing.B) {
+	input := wideObject(1000)
+	keys := []string{"field10", "field500"}
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Parse(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseFields(input, keys); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten turns a nested document into a single-level map whose keys are the
+// dotted paths of its leaves, such as "address.continent" and "districts.0".
+// Empty objects and arrays are kept as leaves. Keys that themselves contain
+// dots cannot be told apart from nesting. A scalar root is stored under "".
+func Flatten(data interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flatten("", data, flat)
+	return flat
+}
+
+func flatten(prefix string, data interface{}, flat map[string]interface{}) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for key, value := range v {
+				flatten(joinKey(prefix, key), value, flat)
+			}
+			return
+		}
+	case *OrderedMap:
+		if v.Len() > 0 {
+			for _, pair := range v.Pairs() {
+				flatten(joinKey(prefix, pair.Key), pair.Value, flat)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, value := range v {
+				flatten(joinKey(prefix, strconv.Itoa(i)), value, flat)
+			}
+			return
+		}
+	}
+	flat[prefix] = data
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// Unflatten rebuilds a nested document from the dotted keys produced by
+// Flatten. A level whose keys are all array indices becomes an array, and
+// its indices must run from 0 without gaps. It is an error for a key to be
+// both a leaf and a prefix of other keys, or for one level to mix indices
+// and names.
+func Unflatten(flat map[string]interface{}) (interface{}, error) {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := &flatNode{}
+	for _, key := range keys {
+		node := root
+		if key != "" {
+			for _, segment := range strings.Split(key, ".") {
+				if node.isLeaf {
+					return nil, fmt.Errorf("unflatten: key %q is used both as a value and as an object or array", node.path)
+				}
+				child, ok := node.children[segment]
+				if !ok {
+					if node.children == nil {
+						node.children = make(map[string]*flatNode)
+					}
+					child = &flatNode{path: joinKey(node.path, segment)}
+					node.children[segment] = child
+				}
+				node = child
+			}
+		}
+		if node.children != nil {
+			return nil, fmt.Errorf("unflatten: key %q is used both as a value and as an object or array", key)
+		}
+		node.isLeaf, node.value = true, flat[key]
+	}
+	if root.children == nil && !root.isLeaf {
+		return map[string]interface{}{}, nil
+	}
+	return root.build()
+}
+
+type flatNode struct {
+	path     string
+	isLeaf   bool
+	value    interface{}
+	children map[string]*flatNode
+}
+
+func (n *flatNode) build() (interface{}, error) {
+	if n.isLeaf {
+		return n.value, nil
+	}
+
+	indices := 0
+	for segment := range n.children {
+		if isIndexKey(segment) {
+			indices++
+		}
+	}
+	if indices == 0 {
+		obj := make(map[string]interface{}, len(n.children))
+		for segment, child := range n.children {
+			value, err := child.build()
+			if err != nil {
+				return nil, err
+			}
+			obj[segment] = value
+		}
+		return obj, nil
+	}
+	if indices != len(n.children) {
+		return nil, fmt.Errorf("unflatten: key %q is used both as an object and as an array", n.path)
+	}
+
+	arr := make([]interface{}, len(n.children))
+	for i := range arr {
+		child, ok := n.children[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("unflatten: array at %q is missing index %d", n.path, i)
+		}
+		value, err := child.build()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = value
+	}
+	return arr, nil
+}
+
+// isIndexKey reports whether a path segment is an array index: digits with
+// no leading zero.
+func isIndexKey(segment string) bool {
+	if segment == "" || (len(segment) > 1 && segment[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(segment); i++ {
+		if !isDigit(rune(segment[i])) {
+			return false
+		}
+	}
+	return true
+}
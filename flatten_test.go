@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	want := map[string]interface{}{
+		"name":              "nepal",
+		"age":               int64(0),
+		"country":           true,
+		"districts.0":       "Kathmandu",
+		"districts.1":       "Lalitpur",
+		"address.continent": "Asia",
+		"address.Location":  "South Asia",
+		"a/b~c":             int64(1),
+	}
+	for _, opts := range []Options{{}, {OrderedObjects: true}} {
+		data, err := ParseWithOptions(sampleDocument, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Flatten(data); !reflect.DeepEqual(got, want) {
+			t.Errorf("Flatten(%+v) = %v, want %v", opts, got, want)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  map[string]interface{}
+	}{
+		{`{"a":{},"b":[],"c":[[1]]}`, map[string]interface{}{"a": map[string]interface{}{}, "b": []interface{}{}, "c.0.0": int64(1)}},
+		{`"s"`, map[string]interface{}{"": "s"}},
+		{`{}`, map[string]interface{}{"": map[string]interface{}{}}},
+	}
+	for _, tt := range tests {
+		if got := Flatten(mustParse(t, tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Flatten(%s) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUnflattenRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		sampleDocument,
+		`{"a":{"b":[{"c":1},{"d":[true,null]}]},"e":{},"f":[]}`,
+		`[[1,2],[3]]`,
+		`"s"`,
+		`{}`,
+		`{"01":"not an index"}`,
+	} {
+		data := mustParse(t, input)
+		got, err := Unflatten(Flatten(data))
+		if err != nil {
+			t.Errorf("Unflatten(Flatten(%s)): %v", input, err)
+		} else if !Equal(got, data) {
+			t.Errorf("Unflatten(Flatten(%s)) = %s", input, mustMarshal(t, got))
+		}
+	}
+	if got, err := Unflatten(map[string]interface{}{}); err != nil || !Equal(got, map[string]interface{}{}) {
+		t.Errorf("Unflatten of an empty map = %v, %v", got, err)
+	}
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	tests := []struct {
+		flat map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"a": 1, "a.b": 2}, `unflatten: key "a" is used both as a value and as an object or array`},
+		{map[string]interface{}{"x.a": 1, "x.a.b": 2}, `unflatten: key "x.a" is used both as a value and as an object or array`},
+		{map[string]interface{}{"": 1, "a": 2}, `unflatten: key "" is used both as a value and as an object or array`},
+		{map[string]interface{}{"a.0": 1, "a.x": 2}, `unflatten: key "a" is used both as an object and as an array`},
+		{map[string]interface{}{"a.1": 1}, `unflatten: array at "a" is missing index 0`},
+	}
+	for _, tt := range tests {
+		_, err := Unflatten(tt.flat)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Unflatten(%v) error = %v, want %s", tt.flat, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "strings"
+
+// Get looks up a dotted path such as "address.continent" or "districts.0" in
+// a parsed value and returns def if any segment is missing. Numeric segments
+// index into arrays. The empty path refers to the whole document.
+func Get(data interface{}, path string, def interface{}) interface{} {
+	if path == "" {
+		return data
+	}
+	current := data
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return def
+			}
+			current = value
+		case *OrderedMap:
+			value, ok := node.Get(segment)
+			if !ok {
+				return def
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(segment, len(node))
+			if err != nil {
+				return def
+			}
+			current = node[index]
+		default:
+			return def
+		}
+	}
+	return current
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	data := mustParse(t, sampleDocument)
+	ordered, err := ParseWithOptions(sampleDocument, Options{OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"name", "nepal"},
+		{"address.continent", "Asia"},
+		{"districts.0", "Kathmandu"},
+		{"districts.1", "Lalitpur"},
+		{"a/b~c", 1.0},
+		{"zip", "default"},
+		{"address.zip", "default"},
+		{"name.first", "default"},
+		{"districts.9", "default"},
+		{"districts.-1", "default"},
+		{"districts.x", "default"},
+	}
+	for _, tt := range tests {
+		for _, doc := range []interface{}{data, ordered} {
+			if got := Get(doc, tt.path, "default"); !Equal(got, tt.want) {
+				t.Errorf("Get(%T, %q) = %v, want %v", doc, tt.path, got, tt.want)
+			}
+		}
+	}
+	if got := Get(data, "", nil); !Equal(got, data) {
+		t.Errorf("Get with an empty path = %v, want the whole document", got)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "sync"
+
+// Interner hands out one canonical string for each distinct value it is
+// given. It is safe for concurrent use, so several parsers may share one,
+// and its zero value is ready to use.
+type Interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+func NewInterner() *Interner {
+	return &Interner{strings: make(map[string]string)}
+}
+
+// Intern returns the canonical instance of s, recording s as canonical if
+// it has not been seen before.
+func (in *Interner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if canonical, ok := in.strings[s]; ok {
+		return canonical
+	}
+	in.record(s)
+	return s
+}
+
+// internBytes is Intern for a string still held as bytes. A string that has
+// been seen before is returned without converting b, so it costs no
+// allocation.
+func (in *Interner) internBytes(b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if canonical, ok := in.strings[string(b)]; ok {
+		return canonical
+	}
+	s := string(b)
+	in.record(s)
+	return s
+}
+
+func (in *Interner) record(s string) {
+	if in.strings == nil {
+		in.strings = make(map[string]string)
+	}
+	in.strings[s] = s
+}
+
+// Len reports how many distinct strings have been interned.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strings)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInternerZeroValue(t *testing.T) {
+	var in Interner
+	a := in.Intern(strings.Repeat("k", 3))
+	b := in.Intern("kkk")
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("Intern returned distinct copies of the same string")
+	}
+	if in.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", in.Len())
+	}
+}
+
+func TestKeyInternerSharesKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain", `[{"name":1},{"name":2}]`},
+		{"escaped", `[{"na\u006de":1},{"n\u0061me":2}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := NewInterner()
+			v, err := ParseWithOptions(tt.input, Options{KeyInterner: in, OrderedObjects: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			arr := v.([]interface{})
+			a := arr[0].(*OrderedMap).Keys()[0]
+			b := arr[1].(*OrderedMap).Keys()[0]
+			if a != "name" || unsafe.StringData(a) != unsafe.StringData(b) {
+				t.Errorf("keys %q and %q do not share storage", a, b)
+			}
+		})
+	}
+}
+
+func TestKeyInternerSavesAllocations(t *testing.T) {
+	input := manyObjects(100)
+	in := NewInterner()
+	opts := Options{KeyInterner: in}
+	ParseWithOptions(input, opts)
+
+	plain := testing.AllocsPerRun(10, func() { Parse(input) })
+	interned := testing.AllocsPerRun(10, func() { ParseWithOptions(input, opts) })
+	// Three keys per object no longer need their own string.
+	if interned > plain-300 {
+		t.Errorf("allocations with interner = %v, without = %v; want at least 300 fewer", interned, plain)
+	}
+}
+
+func manyObjects(n int) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `{"id":%d,"name":"item","active":true}`, i)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func BenchmarkKeyInterner(b *testing.B) {
+	input := manyObjects(10000)
+	b.Run("off", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Parse(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("on", func(b *testing.B) {
+		b.ReportAllocs()
+		opts := Options{KeyInterner: NewInterner()}
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseWithOptions(input, opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
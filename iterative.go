@@ -0,0 +1,78 @@
+package main
+
+// parseIterative produces the same result as parseValue but keeps the
+// objects and arrays being parsed on an explicit stack instead of recursing,
+// so its Go stack use does not grow with the nesting depth.
+func (p *Parser) parseIterative() (interface{}, error) {
+	var stack []*container
+	for {
+		var c *container
+		var value interface{}
+		var err error
+		switch {
+		case p.atRawDepth():
+			value, err = p.parseValue()
+		case p.token.Type == TokenLeftBrace:
+			if c, err = p.openObject(); err != nil {
+				return nil, err
+			}
+			if p.token.Type != TokenRightBrace {
+				if err := p.readKey(c); err != nil {
+					return nil, err
+				}
+				stack = append(stack, c)
+				continue
+			}
+			value, err = p.closeObject(c)
+		case p.token.Type == TokenLeftBracket:
+			if c, err = p.openArray(); err != nil {
+				return nil, err
+			}
+			if p.token.Type != TokenRightBracket {
+				stack = append(stack, c)
+				continue
+			}
+			value, err = p.closeArray(c)
+		default:
+			value, err = p.parseValue()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Hand the finished value to its parent, closing every container
+		// that it completes, until one still expects another value.
+		for {
+			if len(stack) == 0 {
+				return value, nil
+			}
+			c = stack[len(stack)-1]
+			if c.isArray {
+				p.appendElement(c, value)
+				if err := p.arraySeparator(); err != nil {
+					return nil, err
+				}
+				if p.token.Type != TokenRightBracket {
+					break
+				}
+				value, err = p.closeArray(c)
+			} else {
+				p.storeMember(c, value)
+				if err := p.objectSeparator(); err != nil {
+					return nil, err
+				}
+				if p.token.Type != TokenRightBrace {
+					if err := p.readKey(c); err != nil {
+						return nil, err
+					}
+					break
+				}
+				value, err = p.closeObject(c)
+			}
+			if err != nil {
+				return nil, err
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// nested returns depth levels of alternating objects and arrays.
+func nested(depth int) string {
+	var sb strings.Builder
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			sb.WriteString(`{"a":`)
+		} else {
+			sb.WriteString("[1,")
+		}
+	}
+	sb.WriteString(`"leaf"`)
+	for i := depth - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			sb.WriteByte('}')
+		} else {
+			sb.WriteByte(']')
+		}
+	}
+	return sb.String()
+}
+
+func TestIterativeMatchesRecursive(t *testing.T) {
+	inputs := []string{
+		sampleDocument,
+		`[]`,
+		`{}`,
+		`[[[[]]],{},[{}],{"a":{}}]`,
+		`{"a":[1,{"b":[2,[3,{"c":null}]]}],"d":"e"}`,
+		`{"a":1,"a":2}`,
+		` "scalar" `,
+		nested(100),
+		// Errors must match too, including their positions and paths.
+		`[1,2,]`,
+		`{"a":[1,{"b":}]}`,
+		`{"a" 1}`,
+		`[1,[2,[3`,
+		`[{"a":1}}`,
+		`{"a":1,}`,
+		`[1] 2`,
+		"",
+	}
+	optionSets := []Options{
+		{},
+		{OrderedObjects: true},
+		{UseNumber: true, DuplicateKeys: DuplicateKeyError},
+		{AllowTrailingCommas: true, AllowComments: true},
+		{MaxDepth: 3},
+	}
+	for _, opts := range optionSets {
+		iterative := opts
+		iterative.Iterative = true
+		for _, input := range inputs {
+			want, wantErr := ParseWithOptions(input, opts)
+			got, gotErr := ParseWithOptions(input, iterative)
+			if errString(gotErr) != errString(wantErr) {
+				t.Errorf("%+v %.40q: iterative error %v, recursive error %v", opts, input, gotErr, wantErr)
+			} else if wantErr == nil && !Equal(got, want) {
+				t.Errorf("%+v %.40q: iterative result differs", opts, input)
+			}
+		}
+	}
+}
+
+func TestIterativeDeepNesting(t *testing.T) {
+	const depth = 100000
+	input := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+	for _, iterative := range []bool{false, true} {
+		v, err := ParseWithOptions(input, Options{MaxDepth: depth, Iterative: iterative})
+		if err != nil {
+			t.Fatalf("Iterative %t: %v", iterative, err)
+		}
+		for i := 1; i < depth; i++ {
+			v = v.([]interface{})[0]
+		}
+		if arr, ok := v.([]interface{}); !ok || len(arr) != 0 {
+			t.Errorf("Iterative %t: innermost value = %v", iterative, v)
+		}
+	}
+	_, err := ParseWithOptions(input, Options{MaxDepth: depth - 1, Iterative: true})
+	if err == nil || !strings.Contains(err.Error(), "maximum nesting depth") {
+		t.Errorf("iterative parse past MaxDepth error = %v", err)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func BenchmarkNestedDepth5000(b *testing.B) {
+	input := nested(5000)
+	for _, bm := range []struct {
+		name string
+		opts Options
+	}{
+		{"recursive", Options{}},
+		{"iterative", Options{Iterative: true}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseWithOptions(input, bm.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
 )
 
 type TokenType int
@@ -21,211 +29,1674 @@ const (
 	TokenColon
 	TokenComma
 	TokenEOF
+	TokenIdentifier
 )
 
+var tokenTypeNames = [...]string{
+	TokenString:       "String",
+	TokenNumber:       "Number",
+	TokenBoolean:      "Boolean",
+	TokenNull:         "Null",
+	TokenLeftBrace:    "LeftBrace",
+	TokenRightBrace:   "RightBrace",
+	TokenLeftBracket:  "LeftBracket",
+	TokenRightBracket: "RightBracket",
+	TokenColon:        "Colon",
+	TokenComma:        "Comma",
+	TokenEOF:          "EOF",
+	TokenIdentifier:   "Identifier",
+}
+
+func (t TokenType) String() string {
+	if t >= 0 && int(t) < len(tokenTypeNames) {
+		return tokenTypeNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
 type Token struct {
-	Type  TokenType
-	Value string
+	Type   TokenType
+	Value  string
+	Line   int
+	Col    int
+	Offset int
+}
+
+// ParseError describes a syntax error found by the lexer or parser. Line and
+// Col are 1-based and count runes; Offset is the byte offset into the input of
+// the token or character that caused the error. Path locates the failing
+// value within the document, such as $.address.districts[2], and is empty for
+// errors outside any object or array.
+type ParseError struct {
+	Msg    string
+	Line   int
+	Col    int
+	Offset int
+	Path   string
 }
 
+func (e *ParseError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("parse error at %s, line %d, column %d: %s", e.Path, e.Line, e.Col, e.Msg)
+	}
+	return fmt.Sprintf("parse error at line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Lexer scans tokens from an in-memory input or, when reader is set, from a
+// buffer that is refilled as it is consumed. Everything before start (the
+// offset of the token being scanned) or mark, if set, may be discarded on
+// refill; base is the absolute offset of input[0].
 type Lexer struct {
-	input    string
-	pos      int
+	input  []byte
+	reader io.Reader
+	opts   Options
+	err    error
+	base   int
+	pos    int
+	offset int
+	start  int
+	mark   int
+	marked bool
+	// owned is set when input was allocated by the lexer, so Reset may
+	// reuse it.
+	owned bool
+	// aliased is set when input is the memory of a string, which cannot
+	// change, so token values may share it instead of being copied.
+	aliased bool
+	// tokens counts the tokens scanned so far, not including TokenEOF.
+	tokens int
+	// tokenPos is where the token currently being scanned begins.
+	tokenPos position
+	// deferred is set when reading the character after the last token was
+	// put off by advanceEnd.
+	deferred bool
+	// keyInterner is set by the parser while it scans a token that should
+	// be an object key.
+	keyInterner *Interner
+	// comments holds the comments read since the parser last took them,
+	// with Options.PreserveComments.
+	comments []string
+	line     int
+	col      int
 	current  rune
 }
 
+const readChunkSize = 4096
+
 func NewLexer(input string) *Lexer {
-	lexer := &Lexer{input: input, pos: 0}
+	lexer := NewLexerBytes([]byte(input))
+	lexer.owned = true
+	return lexer
+}
+
+// NewLexerBytes scans input in place. The slice must not be modified while
+// the lexer is in use.
+func NewLexerBytes(input []byte) *Lexer {
+	lexer := &Lexer{input: input, pos: 0, line: 1}
 	lexer.advance()
+	lexer.skipBOM()
 	return lexer
 }
 
+// newStringLexer scans input without copying it. Token values are substrings
+// of input.
+func newStringLexer(input string) *Lexer {
+	lexer := &Lexer{input: unsafe.Slice(unsafe.StringData(input), len(input)), aliased: true, line: 1}
+	lexer.advance()
+	lexer.skipBOM()
+	return lexer
+}
+
+func NewLexerReader(r io.Reader) *Lexer {
+	lexer := &Lexer{reader: r, line: 1, owned: true}
+	lexer.advance()
+	lexer.skipBOM()
+	return lexer
+}
+
+// Reset makes the lexer scan input from the beginning, keeping its options.
+// A buffer the lexer allocated itself is reused.
+func (l *Lexer) Reset(input string) {
+	var buf []byte
+	if l.owned {
+		buf = l.input[:0]
+	}
+	*l = Lexer{input: append(buf, input...), opts: l.opts, owned: true, line: 1}
+	l.advance()
+	l.skipBOM()
+}
+
+// skipBOM drops a leading UTF-8 byte order mark, which some tools write at
+// the start of JSON files.
+func (l *Lexer) skipBOM() {
+	if l.current == '\uFEFF' {
+		l.advance()
+		l.col = 1
+	}
+}
+
+type position struct {
+	line, col, offset int
+}
+
+func (l *Lexer) position() position {
+	return position{line: l.line, col: l.col, offset: l.base + l.offset}
+}
+
+func (l *Lexer) errorf(format string, args ...interface{}) error {
+	return l.errorAt(l.position(), format, args...)
+}
+
+func (l *Lexer) errorAt(pos position, format string, args ...interface{}) error {
+	return &ParseError{Msg: fmt.Sprintf(format, args...), Line: pos.line, Col: pos.col, Offset: pos.offset}
+}
+
 func (l *Lexer) advance() {
+	if l.current == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	l.readCurrent()
+}
+
+// advanceEnd moves past a character that ends a token, such as a closing
+// bracket or quote. If reading the next character would mean waiting on the
+// reader, that is put off until the next token is asked for, so a complete
+// value can be returned while the reader has nothing more to send yet.
+func (l *Lexer) advanceEnd() {
+	l.col++
+	if l.reader != nil && !utf8.FullRune(l.input[l.pos:]) {
+		l.offset = l.pos
+		l.current = 0
+		l.deferred = true
+		return
+	}
+	l.readCurrent()
+}
+
+func (l *Lexer) readCurrent() {
+	if l.reader != nil && !utf8.FullRune(l.input[l.pos:]) {
+		l.fill()
+	}
+	l.offset = l.pos
 	if l.pos < len(l.input) {
-		l.current = rune(l.input[l.pos])
-		l.pos++
+		r, width := utf8.DecodeRune(l.input[l.pos:])
+		l.current = r
+		l.pos += width
 	} else {
 		l.current = 0
 	}
 }
 
-func (l *Lexer) skipWhitespace() {
-	for unicode.IsSpace(l.current) {
+func (l *Lexer) fill() {
+	keep := l.start
+	if l.marked && l.mark-l.base < keep {
+		keep = l.mark - l.base
+	}
+	if keep > 0 {
+		n := copy(l.input, l.input[keep:])
+		l.input = l.input[:n]
+		l.base += keep
+		l.pos -= keep
+		l.offset -= keep
+		l.start -= keep
+	}
+
+	for l.reader != nil && !utf8.FullRune(l.input[l.pos:]) {
+		if cap(l.input)-len(l.input) < utf8.UTFMax {
+			buf := make([]byte, len(l.input), 2*cap(l.input)+readChunkSize)
+			copy(buf, l.input)
+			l.input = buf
+		}
+		n, err := l.reader.Read(l.input[len(l.input):cap(l.input)])
+		l.input = l.input[:len(l.input)+n]
+		if err != nil {
+			if err != io.EOF {
+				l.err = err
+			}
+			l.reader = nil
+		}
+		if l.inputTooLarge() {
+			l.err = l.inputSizeError()
+			l.reader = nil
+		}
+	}
+}
+
+// inputTooLarge reports whether more than Options.MaxInputBytes of input have
+// been seen. In reader mode only the bytes read so far count.
+func (l *Lexer) inputTooLarge() bool {
+	return l.opts.MaxInputBytes > 0 && l.base+len(l.input) > l.opts.MaxInputBytes
+}
+
+func (l *Lexer) inputSizeError() error {
+	return l.errorf("input is larger than the maximum of %d bytes", l.opts.MaxInputBytes)
+}
+
+func (l *Lexer) text() string {
+	return l.str(l.input[l.start:l.offset])
+}
+
+// str returns b, a part of the input, as a string.
+func (l *Lexer) str(b []byte) string {
+	if l.aliased && len(b) > 0 {
+		return unsafe.String(&b[0], len(b))
+	}
+	return string(b)
+}
+
+// slice returns a copy of the input between two absolute offsets, which must
+// still be buffered.
+func (l *Lexer) slice(start, end int) []byte {
+	return append([]byte(nil), l.input[start-l.base:end-l.base]...)
+}
+
+func (l *Lexer) atEOF() bool {
+	return l.offset >= len(l.input)
+}
+
+func (l *Lexer) skipWhitespace() error {
+	for {
+		for l.isWhitespace(l.current) {
+			l.advance()
+		}
+		if l.current != '/' || !l.opts.AllowComments {
+			return nil
+		}
+		if err := l.skipComment(); err != nil {
+			return err
+		}
+	}
+}
+
+// isWhitespace accepts the four whitespace characters JSON allows, or any
+// Unicode space when Options.AllowUnicodeWhitespace is set.
+func (l *Lexer) isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r':
+		return true
+	}
+	return l.opts.AllowUnicodeWhitespace && unicode.IsSpace(r)
+}
+
+func (l *Lexer) skipComment() error {
+	pos := l.position()
+	var sb strings.Builder
+	keep := func() {
+		if l.opts.PreserveComments {
+			sb.WriteRune(l.current)
+		}
+	}
+	keep()
+	l.advance()
+
+	switch l.current {
+	case '/':
+		for l.current != '\n' && !l.atEOF() {
+			keep()
+			l.advance()
+		}
+	case '*':
+		keep()
 		l.advance()
+		for {
+			if l.atEOF() {
+				return l.errorAt(pos, "unterminated block comment")
+			}
+			star := l.current == '*'
+			keep()
+			l.advance()
+			if star && l.current == '/' {
+				keep()
+				l.advance()
+				break
+			}
+		}
+	default:
+		return l.errorAt(pos, "unexpected character '/'")
+	}
+	if l.opts.PreserveComments {
+		l.comments = append(l.comments, strings.TrimRight(sb.String(), "\r"))
+	}
+	return nil
+}
+
+func (l *Lexer) nextToken() (Token, error) {
+	if l.deferred {
+		l.deferred = false
+		l.readCurrent()
+	}
+	if l.err == nil && l.inputTooLarge() {
+		return Token{}, l.inputSizeError()
+	}
+	if err := l.skipWhitespace(); err != nil {
+		return Token{}, err
+	}
+	l.start = l.offset
+	l.tokenPos = l.position()
+	token, err := l.scanToken()
+	if l.err != nil {
+		return Token{}, l.err
 	}
+	if err != nil {
+		return Token{}, err
+	}
+	if token.Type != TokenEOF {
+		l.tokens++
+		if l.opts.MaxTokens > 0 && l.tokens > l.opts.MaxTokens {
+			l.err = l.errorAt(l.tokenPos, "document has more than the maximum of %d tokens", l.opts.MaxTokens)
+			return Token{}, l.err
+		}
+	}
+	token.Line, token.Col, token.Offset = l.tokenPos.line, l.tokenPos.col, l.tokenPos.offset
+	return token, nil
 }
 
-func (l *Lexer) nextToken() Token {
-	l.skipWhitespace()
+// Next returns the next token in the input, including its position. Once the
+// input is exhausted it keeps returning a TokenEOF token.
+func (l *Lexer) Next() (Token, error) {
+	return l.nextToken()
+}
 
+// Tokens scans all of input and returns its tokens, ending with TokenEOF.
+func Tokens(input string) ([]Token, error) {
+	lexer := NewLexer(input)
+	var tokens []Token
+	for {
+		token, err := lexer.Next()
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+		if token.Type == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *Lexer) scanToken() (Token, error) {
 	switch l.current {
 	case '{':
 		l.advance()
-		return Token{Type: TokenLeftBrace, Value: "{"}
+		return Token{Type: TokenLeftBrace, Value: "{"}, nil
 	case '}':
-		l.advance()
-		return Token{Type: TokenRightBrace, Value: "}"}
+		l.advanceEnd()
+		return Token{Type: TokenRightBrace, Value: "}"}, nil
 	case '[':
 		l.advance()
-		return Token{Type: TokenLeftBracket, Value: "["}
+		return Token{Type: TokenLeftBracket, Value: "["}, nil
 	case ']':
-		l.advance()
-		return Token{Type: TokenRightBracket, Value: "]"}
+		l.advanceEnd()
+		return Token{Type: TokenRightBracket, Value: "]"}, nil
 	case ':':
 		l.advance()
-		return Token{Type: TokenColon, Value: ":"}
+		return Token{Type: TokenColon, Value: ":"}, nil
 	case ',':
 		l.advance()
-		return Token{Type: TokenComma, Value: ","}
+		return Token{Type: TokenComma, Value: ","}, nil
 	case '"':
-		return l.readString()
+		return l.readString('"')
+	case '\'':
+		if l.opts.AllowSingleQuotes {
+			return l.readString('\'')
+		}
 	default:
-		if unicode.IsDigit(l.current) || l.current == '-' {
+		if l.atEOF() {
+			return Token{Type: TokenEOF, Value: ""}, nil
+		}
+		if isDigit(l.current) || l.current == '-' || l.current == '+' || l.current == '.' {
 			return l.readNumber()
-		} else if unicode.IsLetter(l.current) {
+		} else if unicode.IsLetter(l.current) || (l.opts.AllowUnquotedKeys && (l.current == '_' || l.current == '$')) {
 			return l.readKeyword()
 		}
 	}
 
-	return Token{Type: TokenEOF, Value: ""}
+	return Token{}, l.errorf("unexpected character %q", l.current)
 }
 
-func (l *Lexer) readString() Token {
+// readString scans a string literal. Until it meets an escape sequence or an
+// invalid byte the value is taken straight from the input, so a plain string
+// costs a single allocation, or none when it is a key found in keyInterner.
+func (l *Lexer) readString(quote rune) (Token, error) {
 	var sb strings.Builder
+	pos := l.position()
 	l.advance()
+	start := l.base + l.offset
+	building := false
 
-	for l.current != '"' && l.current != 0 {
+	length := 0
+	for l.current != quote {
+		switch {
+		case l.atEOF():
+			return Token{}, l.errorAt(pos, "unterminated string literal")
+		case l.current < 0x20:
+			return Token{}, l.errorf("unescaped control character U+%04X in string literal", l.current)
+		}
+		if length++; l.opts.MaxStringLength > 0 && length > l.opts.MaxStringLength {
+			return Token{}, l.errorAt(pos, "string literal longer than the maximum of %d characters", l.opts.MaxStringLength)
+		}
+		if !building && (l.current == '\\' || l.current == utf8.RuneError) {
+			sb.Write(l.input[start-l.base : l.offset])
+			building = true
+		}
+		if l.current == '\\' {
+			escPos := l.position()
+			l.advance()
+			if err := l.readEscape(&sb, escPos); err != nil {
+				return Token{}, err
+			}
+			continue
+		}
+		if building {
+			sb.WriteRune(l.current)
+		}
+		l.advance()
+	}
+
+	var value string
+	switch {
+	case building && l.keyInterner != nil:
+		value = l.keyInterner.Intern(sb.String())
+	case building:
+		value = sb.String()
+	case l.keyInterner != nil:
+		value = l.keyInterner.internBytes(l.input[start-l.base : l.offset])
+	default:
+		value = l.str(l.input[start-l.base : l.offset])
+	}
+	l.advanceEnd()
+
+	return Token{Type: TokenString, Value: value}, nil
+}
+
+func (l *Lexer) readEscape(sb *strings.Builder, pos position) error {
+	if l.atEOF() {
+		return l.errorf("unexpected end of input in escape sequence")
+	}
+	switch l.current {
+	case '"', '\\', '/':
+		sb.WriteRune(l.current)
+	case '\'':
+		if !l.opts.AllowSingleQuotes {
+			return l.errorAt(pos, "invalid escape sequence \\'")
+		}
 		sb.WriteRune(l.current)
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		l.advance()
+		return l.readUnicodeEscape(sb)
+	default:
+		if l.opts.JSON5Strings {
+			return l.readJSON5Escape(sb, pos)
+		}
+		return l.invalidEscape(pos)
+	}
+	l.advance()
+	return nil
+}
+
+func (l *Lexer) invalidEscape(pos position) error {
+	if !unicode.IsPrint(l.current) {
+		return l.errorAt(pos, "invalid escape sequence: '\\' followed by U+%04X", l.current)
+	}
+	return l.errorAt(pos, "invalid escape sequence \\%c", l.current)
+}
+
+// readJSON5Escape handles the escapes JSON5 adds: \0, \v, \xHH and a
+// backslash before a line terminator, which continues the string on the next
+// line.
+func (l *Lexer) readJSON5Escape(sb *strings.Builder, pos position) error {
+	switch l.current {
+	case '0':
+		l.advance()
+		if isDigit(l.current) {
+			return l.errorAt(pos, "invalid escape sequence \\0%c", l.current)
+		}
+		sb.WriteByte(0)
+	case 'v':
+		l.advance()
+		sb.WriteByte('\v')
+	case 'x':
+		l.advance()
+		r, err := l.readHex(2, 'x')
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+	case '\r':
+		l.advance()
+		if l.current == '\n' {
+			l.advance()
+		}
+	case '\n', '\u2028', '\u2029':
+		l.advance()
+	default:
+		return l.invalidEscape(pos)
+	}
+	return nil
+}
+
+// readUnicodeEscape reads the hex digits of a \u escape, and the low half of
+// a surrogate pair if the first half is a high surrogate. With
+// Options.ReplaceInvalidUnicode, a surrogate that is not part of a pair is
+// written as U+FFFD instead of being an error.
+func (l *Lexer) readUnicodeEscape(sb *strings.Builder) error {
+	r, err := l.readHex(4, 'u')
+	if err != nil {
+		return err
+	}
+	for {
+		switch {
+		case !utf16.IsSurrogate(r):
+			sb.WriteRune(r)
+			return nil
+		case r >= 0xDC00:
+			return l.loneSurrogate(sb, "unexpected low surrogate \\u%04X", r)
+		case l.current != '\\':
+			return l.loneSurrogate(sb, "missing low surrogate after \\u%04X", r)
+		}
+		escPos := l.position()
+		l.advance()
+		if l.current != 'u' {
+			if err := l.loneSurrogate(sb, "missing low surrogate after \\u%04X", r); err != nil {
+				return err
+			}
+			return l.readEscape(sb, escPos)
+		}
+		l.advance()
+		low, err := l.readHex(4, 'u')
+		if err != nil {
+			return err
+		}
+		if combined := utf16.DecodeRune(r, low); combined != unicode.ReplacementChar {
+			sb.WriteRune(combined)
+			return nil
+		}
+		if !l.opts.ReplaceInvalidUnicode {
+			return l.errorf("invalid surrogate pair \\u%04X\\u%04X", r, low)
+		}
+		// The second escape may still stand on its own or start a pair.
+		sb.WriteRune(unicode.ReplacementChar)
+		r = low
+	}
+}
+
+// loneSurrogate writes U+FFFD for the unpaired surrogate r with
+// Options.ReplaceInvalidUnicode, or reports it as an error otherwise.
+func (l *Lexer) loneSurrogate(sb *strings.Builder, format string, r rune) error {
+	if !l.opts.ReplaceInvalidUnicode {
+		return l.errorf(format, r)
+	}
+	sb.WriteRune(unicode.ReplacementChar)
+	return nil
+}
+
+// readHex reads the n hex digits of a \u or, in JSON5 strings, \x escape.
+func (l *Lexer) readHex(n int, kind rune) (rune, error) {
+	var r rune
+	for i := 0; i < n; i++ {
+		var digit rune
+		switch {
+		case l.atEOF():
+			return 0, l.errorf("unexpected end of input in \\%c escape", kind)
+		case l.current >= '0' && l.current <= '9':
+			digit = l.current - '0'
+		case l.current >= 'a' && l.current <= 'f':
+			digit = l.current - 'a' + 10
+		case l.current >= 'A' && l.current <= 'F':
+			digit = l.current - 'A' + 10
+		default:
+			return 0, l.errorf("invalid hex digit %q in \\%c escape", l.current, kind)
+		}
+		r = r<<4 | digit
+		l.advance()
+	}
+	return r, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func (l *Lexer) readNumber() (Token, error) {
+	pos := l.position()
+	if l.current == '+' && !l.opts.JSON5Numbers {
+		return Token{}, l.errorAt(pos, "invalid number: leading '+' not allowed")
+	}
+	if l.current == '-' || l.current == '+' {
 		l.advance()
+		if l.current == 'I' {
+			return l.readKeyword()
+		}
+	}
+
+	intDigits := true
+	switch {
+	case l.current == '0':
+		l.advance()
+		if l.current == 'x' || l.current == 'X' {
+			token, err := l.readHexNumber(pos)
+			token.Value = strings.TrimPrefix(token.Value, "+")
+			return token, err
+		}
+		if isDigit(l.current) {
+			return Token{}, l.errorAt(pos, "invalid number: leading zeros not allowed")
+		}
+	case isDigit(l.current):
+		if err := l.skipDigits(pos); err != nil {
+			return Token{}, err
+		}
+	case l.current == '.' && l.opts.JSON5Numbers:
+		intDigits = false
+	case l.current == '.':
+		return Token{}, l.errorAt(pos, "invalid number: missing digit before '.'")
+	default:
+		return Token{}, l.errorAt(pos, "invalid number %q: expected digit", l.text())
+	}
+
+	if l.current == '.' {
+		l.advance()
+		if isDigit(l.current) {
+			if err := l.skipDigits(pos); err != nil {
+				return Token{}, err
+			}
+		} else if !l.opts.JSON5Numbers || !intDigits {
+			return Token{}, l.errorAt(pos, "invalid number %q: expected digit after '.'", l.text())
+		}
+	}
+
+	if l.current == 'e' || l.current == 'E' {
+		l.advance()
+		if l.current == '+' || l.current == '-' {
+			l.advance()
+		}
+		if !isDigit(l.current) {
+			return Token{}, l.errorAt(pos, "invalid number %q: expected digit in exponent", l.text())
+		}
+		if err := l.skipDigits(pos); err != nil {
+			return Token{}, err
+		}
+	}
+
+	if l.opts.JSON5Numbers {
+		return Token{Type: TokenNumber, Value: normalizeNumber(l.text())}, nil
+	}
+	return Token{Type: TokenNumber, Value: l.text()}, nil
+}
+
+// normalizeNumber rewrites the JSON5 forms +1, .5 and 5. as the JSON numbers
+// 1, 0.5 and 5, so that Number and Decimal values marshal as valid JSON.
+func normalizeNumber(text string) string {
+	text = strings.TrimPrefix(text, "+")
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		if i == 0 || text[i-1] == '-' {
+			text = text[:i] + "0" + text[i:]
+			i++
+		}
+		if i+1 == len(text) || !isDigit(rune(text[i+1])) {
+			text = text[:i] + text[i+1:]
+		}
+	}
+	return text
+}
+
+func (l *Lexer) readHexNumber(pos position) (Token, error) {
+	if !l.opts.AllowHexNumbers {
+		return Token{}, l.errorAt(pos, "invalid number: hexadecimal literals are not allowed unless AllowHexNumbers is set")
 	}
 	l.advance()
+	if !isHexDigit(l.current) {
+		return Token{}, l.errorAt(pos, "invalid number %q: expected hex digit", l.text())
+	}
+	for isHexDigit(l.current) {
+		l.advance()
+		if l.numberTooLong() {
+			return Token{}, l.numberLengthError(pos)
+		}
+	}
+	return Token{Type: TokenNumber, Value: hexToDecimal(l.text())}, nil
+}
 
-	return Token{Type: TokenString, Value: sb.String()}
+// hexToDecimal rewrites a hexadecimal literal such as -0x1F as the decimal
+// JSON number -31, so that Number and Decimal values hold valid JSON.
+func hexToDecimal(text string) string {
+	neg := strings.HasPrefix(text, "-")
+	digits := strings.TrimLeft(text, "+-")[2:]
+	n, _ := new(big.Int).SetString(digits, 16)
+	if neg {
+		n.Neg(n)
+	}
+	return n.String()
 }
 
-func (l *Lexer) readNumber() Token {
-	start := l.pos - 1
-	for unicode.IsDigit(l.current) || l.current == '.' || l.current == '-' {
+func isHexDigit(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func (l *Lexer) skipDigits(pos position) error {
+	for isDigit(l.current) {
 		l.advance()
+		if l.numberTooLong() {
+			return l.numberLengthError(pos)
+		}
 	}
-	value := l.input[start : l.pos-1]
-	return Token{Type: TokenNumber, Value: value}
+	return nil
 }
 
-func (l *Lexer) readKeyword() Token {
-	start := l.pos - 1
-	for unicode.IsLetter(l.current) {
+// numberTooLong reports whether the number being scanned has grown past
+// Options.MaxNumberLength. Number characters are all ASCII, so bytes and
+// characters coincide.
+func (l *Lexer) numberTooLong() bool {
+	return l.opts.MaxNumberLength > 0 && l.offset-l.start > l.opts.MaxNumberLength
+}
+
+func (l *Lexer) numberLengthError(pos position) error {
+	return l.errorAt(pos, "invalid number: longer than the maximum of %d characters", l.opts.MaxNumberLength)
+}
+
+func (l *Lexer) readKeyword() (Token, error) {
+	pos := l.tokenPos
+	for isIdentifierRune(l.current) {
 		l.advance()
 	}
-	value := l.input[start : l.pos-1]
+	value := l.text()
 
 	switch value {
 	case "true", "false":
-		return Token{Type: TokenBoolean, Value: value}
+		return Token{Type: TokenBoolean, Value: value}, nil
 	case "null":
-		return Token{Type: TokenNull, Value: value}
+		return Token{Type: TokenNull, Value: value}, nil
+	case "NaN", "Infinity", "-Infinity", "+Infinity":
+		if l.opts.AllowSpecialFloats {
+			return Token{Type: TokenNumber, Value: strings.TrimPrefix(value, "+")}, nil
+		}
+		return Token{}, l.errorAt(pos, "%s is not valid JSON unless AllowSpecialFloats is set", value)
+	}
+	if value[0] == '-' || value[0] == '+' {
+		// readNumber hands over a signed word in case it is Infinity; any
+		// other one is a malformed number, never an identifier.
+		return Token{}, l.errorAt(pos, "invalid number %q", value)
 	}
-	panic("Unexpected keyword: " + value)
+	lower := strings.ToLower(value)
+	if l.opts.CaseInsensitiveLiterals {
+		switch lower {
+		case "true", "false":
+			return Token{Type: TokenBoolean, Value: lower}, nil
+		case "null":
+			return Token{Type: TokenNull, Value: lower}, nil
+		}
+	}
+	if l.opts.AllowUnquotedKeys {
+		return Token{Type: TokenIdentifier, Value: value}, nil
+	}
+	switch lower {
+	case "true", "false", "null":
+		return Token{}, l.errorAt(pos, "invalid literal %q (did you mean %q?)", value, lower)
+	}
+	return Token{}, l.errorAt(pos, "invalid literal %q", value)
+}
+
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// DuplicateKeyPolicy says how the parser treats a key that appears more than
+// once in the same object.
+type DuplicateKeyPolicy int
+
+const (
+	// LastWins keeps the value of the last occurrence of the key.
+	LastWins DuplicateKeyPolicy = iota
+	// FirstWins keeps the value of the first occurrence and ignores the
+	// rest.
+	FirstWins
+	// DuplicateKeyError makes a repeated key a parse error.
+	DuplicateKeyError
+)
+
+type Options struct {
+	// UseNumber makes the parser return numbers as Number instead of
+	// int64/float64, keeping their exact source text.
+	UseNumber bool
+	// UseDecimalStrings makes the parser return numbers as Decimal, keeping
+	// their exact source text. It takes precedence over UseNumber.
+	UseDecimalStrings bool
+	// DuplicateKeys chooses what happens when a key is repeated within one
+	// object. By default the last occurrence wins.
+	DuplicateKeys DuplicateKeyPolicy
+	// MaxDepth limits how deeply objects and arrays may nest. Zero means
+	// defaultMaxDepth.
+	MaxDepth int
+	// AllowComments lets // line comments and /* */ block comments appear
+	// anywhere whitespace is allowed.
+	AllowComments bool
+	// PreserveComments implies AllowComments and OrderedObjects and keeps
+	// the comments instead of skipping them, in the OrderedMaps of the
+	// result. Each comment goes with what follows it: the next key, the
+	// value after a colon, an object's opening or closing brace, or the end
+	// of the top-level object. Comments inside arrays are kept with the
+	// member holding the array. Only comments in a top-level array or around
+	// a top-level scalar, which have no OrderedMap to hold them, are
+	// dropped. MarshalOptions.EmitComments writes the kept comments back out.
+	PreserveComments bool
+	// AllowTrailingCommas accepts a comma after the last element of an
+	// object or array.
+	AllowTrailingCommas bool
+	// RawDepth, when positive, makes every value nested RawDepth containers
+	// deep come back as a RawMessage holding its source text instead of
+	// being decoded. RawDepth 1 leaves the members of the root undecoded.
+	RawDepth int
+	// OrderedObjects makes objects decode to *OrderedMap, which remembers
+	// the order keys appeared in, instead of map[string]interface{}.
+	OrderedObjects bool
+	// AllowSpecialFloats accepts the NaN, Infinity and -Infinity literals
+	// some encoders emit, decoding them to the matching float64 values.
+	AllowSpecialFloats bool
+	// AllowSingleQuotes accepts 'single-quoted' strings, which support the
+	// same escapes as double-quoted ones plus \'.
+	AllowSingleQuotes bool
+	// AllowUnquotedKeys accepts identifiers such as {name: "x"} as object
+	// keys.
+	AllowUnquotedKeys bool
+	// AllowHexNumbers accepts hexadecimal integers such as 0xFF and -0x1A.
+	// They are read as their decimal value, so with UseNumber 0xFF becomes
+	// Number("255").
+	AllowHexNumbers bool
+	// KeyInterner, when set, makes object keys share one string per distinct
+	// key. Reusing the same Interner across documents with repeated field
+	// names keeps a single copy of each name alive.
+	KeyInterner *Interner
+	// MaxNumberLength, when positive, rejects number literals longer than
+	// this many characters before they are converted.
+	MaxNumberLength int
+	// MaxStringLength, when positive, rejects string literals, keys
+	// included, holding more than this many characters. An escape sequence
+	// counts as one character. Scanning stops as soon as the limit is passed.
+	MaxStringLength int
+	// ReplaceInvalidUnicode decodes a \u escape of a surrogate that is not
+	// part of a valid pair as U+FFFD instead of rejecting it.
+	ReplaceInvalidUnicode bool
+	// MaxTokens, when positive, limits how many tokens a document may hold.
+	MaxTokens int
+	// MaxInputBytes, when positive, limits the size of the input. Readers
+	// are not read further once the limit is passed.
+	MaxInputBytes int
+	// AllowUnicodeWhitespace treats every Unicode space character, such as
+	// U+00A0 NO-BREAK SPACE, as whitespace between tokens. By default only
+	// space, tab, CR and LF are.
+	AllowUnicodeWhitespace bool
+	// CaseInsensitiveLiterals accepts true, false and null in any case, such
+	// as True or NULL.
+	CaseInsensitiveLiterals bool
+	// NumberConverter, when set, is called with the source text of every
+	// number and its result used as the value. It takes precedence over
+	// UseNumber and UseDecimalStrings.
+	NumberConverter func(raw string) (interface{}, error)
+	// JSON5Strings accepts the extra string escapes of JSON5: \0, \v, \xHH
+	// and line continuations.
+	JSON5Strings bool
+	// JSON5Numbers accepts the extra number forms of JSON5: a leading '+',
+	// and a '.' with no digits before or after it, as in .5 and 5.
+	JSON5Numbers bool
+	// NullValue is what null decodes to. It defaults to nil.
+	NullValue interface{}
+	// TagTypes wraps every string, number, boolean and null in a TypedValue
+	// recording its JSON type.
+	TagTypes bool
+	// BigIntFallback makes integers that do not fit in an int64 decode to
+	// *big.Int instead of a rounded float64.
+	BigIntFallback bool
+	// Iterative parses with an explicit stack instead of recursion, which
+	// keeps stack use flat for deeply nested documents. The result is the
+	// same either way; MaxDepth still applies.
+	Iterative bool
+	// CollectAllErrors makes the parser carry on after a syntax error,
+	// skipping to the next ',' or closing bracket of the enclosing object or
+	// array, and report every error it met as a ParseErrors. The value
+	// returned alongside holds whatever could be parsed; if the input ends
+	// inside objects or arrays, they are returned with the members read so
+	// far. It implies the recursive parser even if Iterative is set.
+	CollectAllErrors bool
 }
 
+const defaultMaxDepth = 10000
+
 type Parser struct {
-	lexer *Lexer
-	token Token
+	lexer   *Lexer
+	token   Token
+	prevEnd int
+	opts    Options
+	depth   int
+	discard bool
+	handler EventHandler
+	stats   *Stats
+	path    []pathSegment
+	// lexFailed is set when the last token could not be scanned, leaving
+	// token stale.
+	lexFailed bool
+	// valueOnly stops the parser from scanning past the end of the
+	// top-level value, for ParseAt.
+	valueOnly bool
+	// abandoned is set when recovery runs out of input, so the containers
+	// still open are returned as they are.
+	abandoned bool
+	// errs collects the errors recovered from under CollectAllErrors.
+	errs []ParseError
+	ctx  context.Context
+	// commentOwner is the innermost object being parsed, which takes the
+	// comments inside its current member's value, with PreserveComments.
+	commentOwner *container
+}
+
+// ctxCheckInterval is how many tokens the parser reads between checks of its
+// context for cancellation.
+const ctxCheckInterval = 1024
+
+func NewParser(lexer *Lexer) (*Parser, error) {
+	return NewParserWithOptions(lexer, Options{})
+}
+
+func NewParserWithOptions(lexer *Lexer, opts Options) (*Parser, error) {
+	if opts.PreserveComments {
+		opts.AllowComments, opts.OrderedObjects = true, true
+	}
+	lexer.opts = opts
+	p := &Parser{lexer: lexer, opts: opts}
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func Parse(input string) (interface{}, error) {
+	return ParseWithOptions(input, Options{})
+}
+
+func ParseBytes(input []byte) (interface{}, error) {
+	parser, err := NewParser(NewLexerBytes(input))
+	if err != nil {
+		return nil, err
+	}
+	return parser.parseDocument()
+}
+
+func ParseWithOptions(input string, opts Options) (interface{}, error) {
+	parser, err := NewParserWithOptions(NewLexer(input), opts)
+	if err != nil {
+		return nil, err
+	}
+	return parser.parseDocument()
+}
+
+// ParseContext is like Parse but gives up with ctx.Err() once ctx is done.
+func ParseContext(ctx context.Context, input string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	parser, err := NewParser(NewLexer(input))
+	if err != nil {
+		return nil, err
+	}
+	parser.ctx = ctx
+	return parser.parseDocument()
+}
+
+// ParseAt parses the single value that starts at byte offset offset of input,
+// ignoring whatever follows it, and returns the offset just past its end.
+// This suits JSON embedded in a larger text, such as a log line. Error
+// positions are relative to the whole input.
+func ParseAt(input string, offset int) (value interface{}, end int, err error) {
+	if offset < 0 || offset > len(input) {
+		return nil, 0, fmt.Errorf("offset %d out of range for input of length %d", offset, len(input))
+	}
+	lineStart := strings.LastIndexByte(input[:offset], '\n') + 1
+	lexer := &Lexer{
+		input: []byte(input[offset:]),
+		base:  offset,
+		owned: true,
+		line:  1 + strings.Count(input[:lineStart], "\n"),
+		col:   utf8.RuneCountInString(input[lineStart:offset]),
+	}
+	lexer.advance()
+	parser, err := NewParser(lexer)
+	if err != nil {
+		return nil, 0, err
+	}
+	parser.valueOnly = true
+	value, err = parser.parseJSON()
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, parser.prevEnd, nil
+}
+
+// Reset prepares the parser to parse input, keeping its options, so that one
+// parser can be reused for many documents.
+func (p *Parser) Reset(input string) error {
+	p.lexer.Reset(input)
+	*p = Parser{lexer: p.lexer, opts: p.opts, path: p.path[:0]}
+	return p.nextToken()
+}
+
+// Parse parses the whole input as a single document.
+func (p *Parser) Parse() (interface{}, error) {
+	return p.parseDocument()
+}
+
+func (p *Parser) parseDocument() (interface{}, error) {
+	if p.opts.CollectAllErrors {
+		return p.parseCollectingErrors()
+	}
+	value, err := p.parseJSON()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.Type != TokenEOF {
+		return nil, p.errorf("unexpected trailing content after top-level value")
+	}
+	p.keepEndComments(value)
+	return value, nil
+}
+
+// keepEndComments gives the comments after the top-level value to it, if it
+// is an OrderedMap.
+func (p *Parser) keepEndComments(value interface{}) {
+	if comments := p.takeComments(); comments != nil {
+		if m, ok := value.(*OrderedMap); ok {
+			m.SetEndComments(comments)
+		}
+	}
+}
+
+func (p *Parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.token, format, args...)
+}
+
+func (p *Parser) errorAt(token Token, format string, args ...interface{}) error {
+	return &ParseError{Msg: fmt.Sprintf(format, args...), Line: token.Line, Col: token.Col, Offset: token.Offset, Path: p.currentPath()}
 }
 
-func NewParser(lexer *Lexer) *Parser {
-	p := &Parser{lexer: lexer}
-	p.nextToken()
-	return p
+// currentPath renders the key and index stack of the value being parsed, or
+// returns "" at the top level.
+func (p *Parser) currentPath() string {
+	if len(p.path) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, segment := range p.path {
+		switch {
+		case segment.isIndex:
+			fmt.Fprintf(&sb, "[%d]", segment.index)
+		case isPathKey(segment.key):
+			sb.WriteByte('.')
+			sb.WriteString(segment.key)
+		default:
+			fmt.Fprintf(&sb, "[%q]", segment.key)
+		}
+	}
+	return sb.String()
 }
 
-func (p *Parser) nextToken() {
-	p.token = p.lexer.nextToken()
+func isPathKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !isIdentifierRune(r) {
+			return false
+		}
+	}
+	return true
 }
 
-func (p *Parser) parseJSON() interface{} {
+func (p *Parser) nextToken() error {
+	if p.ctx != nil && p.lexer.tokens%ctxCheckInterval == 0 {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	p.prevEnd = p.lexer.base + p.lexer.offset
+	if p.valueOnly && p.depth == 0 {
+		p.token = Token{Type: TokenEOF, Offset: p.prevEnd}
+		return nil
+	}
+	token, err := p.lexer.nextToken()
+	p.lexFailed = err != nil
+	if err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			perr.Path = p.currentPath()
+		}
+		return err
+	}
+	p.token = token
+	return nil
+}
+
+func (p *Parser) parseJSON() (interface{}, error) {
 	switch p.token.Type {
-	case TokenLeftBrace:
-		return p.parseObject()
-	case TokenLeftBracket:
-		return p.parseArray()
+	case TokenEOF:
+		return nil, p.errorf("unexpected end of input: empty document")
+	case TokenColon, TokenComma, TokenRightBrace, TokenRightBracket:
+		return nil, p.errorf("unexpected %s at start of document", describeToken(p.token))
+	}
+	if p.opts.Iterative && !p.opts.CollectAllErrors {
+		return p.parseIterative()
+	}
+	return p.parseValue()
+}
+
+func (p *Parser) enter() error {
+	maxDepth := p.opts.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if p.depth >= maxDepth {
+		return p.errorf("maximum nesting depth of %d exceeded", maxDepth)
+	}
+	p.depth++
+	if p.stats != nil && p.depth > p.stats.MaxDepth {
+		p.stats.MaxDepth = p.depth
+	}
+	return nil
+}
+
+func (p *Parser) leave() {
+	p.depth--
+}
+
+func (p *Parser) parseObject() (interface{}, error) {
+	c, err := p.openObject()
+	if c == nil {
+		return nil, err
+	}
+	if err != nil {
+		if err := p.resync(err, p.depth, len(p.path), TokenRightBrace); err != nil {
+			return nil, err
+		}
+	}
+	for !p.abandoned && p.token.Type != TokenRightBrace {
+		depth, pathLen := p.depth, len(p.path)
+		if err := p.parseMember(c); err != nil {
+			if err := p.resync(err, depth, pathLen, TokenRightBrace); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.abandoned {
+		p.leave()
+		return c.value(), nil
+	}
+	return p.closeObject(c)
+}
+
+func (p *Parser) parseMember(c *container) error {
+	if err := p.readKey(c); err != nil {
+		return err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	p.storeMember(c, value)
+	if p.abandoned {
+		return nil
+	}
+	return p.objectSeparator()
+}
+
+// container is an object or array whose members are still being parsed.
+type container struct {
+	isArray bool
+	obj     map[string]interface{}
+	ordered *OrderedMap
+	arr     []interface{}
+	key     string
+	// comments are those read before key, and valueComments those read
+	// since it inside its value, with Options.PreserveComments.
+	comments      []string
+	valueComments []string
+	// outer is the object whose member holds this one, while preserving
+	// comments.
+	outer *container
+	// seen records the keys read so far, whether or not they are stored,
+	// unless DuplicateKeys is LastWins. duplicate is set when key is one of
+	// them.
+	seen      map[string]bool
+	duplicate bool
+}
+
+func (c *container) value() interface{} {
+	switch {
+	case c.isArray:
+		return c.arr
+	case c.ordered != nil:
+		return c.ordered
+	}
+	return c.obj
+}
+
+// openObject consumes the '{' of an object and leaves the parser on its
+// first key or its closing brace. If reading that token fails, the container
+// is returned along with the error so that recovery can carry on inside it.
+func (p *Parser) openObject() (*container, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	c := &container{}
+	switch {
+	case p.discard:
+	case p.opts.OrderedObjects:
+		c.ordered = NewOrderedMap()
 	default:
-		panic("Invalid JSON start")
+		c.obj = make(map[string]interface{})
+	}
+	if leading := p.takeComments(); leading != nil && c.ordered != nil {
+		c.ordered.SetLeadingComments(leading)
+	}
+	if p.opts.PreserveComments {
+		c.outer, p.commentOwner = p.commentOwner, c
+	}
+	if p.stats != nil {
+		p.stats.NumObjects++
+	}
+	if p.handler != nil {
+		if err := p.handler.OnObjectStart(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.nextKeyToken(); err != nil {
+		return c, err
 	}
+	return c, nil
 }
 
-func (p *Parser) parseObject() map[string]interface{} {
-	obj := make(map[string]interface{})
-	p.nextToken()
+// readKey consumes a member's key and colon, leaving the parser on its value.
+func (p *Parser) readKey(c *container) error {
+	if p.token.Type == TokenEOF {
+		return p.errorf("unexpected end of input: missing '}' to close object")
+	}
+	if !p.isKey(p.token) {
+		return p.errorf("expected string key in object")
+	}
+	c.comments, c.valueComments = p.takeComments(), nil
+	key := p.token.Value
+	if p.opts.KeyInterner != nil && p.token.Type != TokenString {
+		// Quoted keys are interned by the lexer as they are scanned.
+		key = p.opts.KeyInterner.Intern(key)
+	}
+	if p.opts.DuplicateKeys != LastWins {
+		if c.seen == nil {
+			c.seen = make(map[string]bool)
+		}
+		c.duplicate = c.seen[key]
+		if c.duplicate && p.opts.DuplicateKeys == DuplicateKeyError {
+			return p.errorf("duplicate key %q", key)
+		}
+		c.seen[key] = true
+	}
+	if p.handler != nil {
+		if err := p.handler.OnKey(key); err != nil {
+			return err
+		}
+	}
+	c.key = key
+	p.path = append(p.path, pathSegment{key: key})
+	if err := p.nextToken(); err != nil {
+		return err
+	}
 
-	for p.token.Type != TokenRightBrace {
-		if p.token.Type != TokenString {
-			panic("Expected string key in object")
+	if p.token.Type != TokenColon {
+		return p.errorf("expected ':' after key %q, found %s", key, describeToken(p.token))
+	}
+	if err := p.nextToken(); err != nil {
+		return err
+	}
+	switch p.token.Type {
+	case TokenComma, TokenRightBrace, TokenEOF:
+		return p.errorf("missing value for key %q", key)
+	case TokenColon:
+		return p.errorf("unexpected second ':' after key %q", key)
+	}
+	return nil
+}
+
+func (p *Parser) storeMember(c *container, value interface{}) {
+	p.path = p.path[:len(p.path)-1]
+	if c.duplicate {
+		return
+	}
+	if c.ordered != nil {
+		c.ordered.Set(c.key, value)
+		if c.comments != nil {
+			c.ordered.SetComments(c.key, c.comments)
+		}
+		if c.valueComments != nil {
+			c.ordered.SetValueComments(c.key, c.valueComments)
 		}
-		key := p.token.Value
-		p.nextToken()
+	} else if !p.discard {
+		c.obj[c.key] = value
+	}
+}
 
-		if p.token.Type != TokenColon {
-			panic("Expected ':' after key")
+// objectSeparator consumes the comma after a member, if there is one, and
+// leaves the parser on the next key or the closing brace.
+func (p *Parser) objectSeparator() error {
+	if p.token.Type == TokenComma {
+		if err := p.nextKeyToken(); err != nil {
+			return err
+		}
+		if p.token.Type == TokenRightBrace && !p.opts.AllowTrailingCommas {
+			return p.errorf("trailing comma before '}'")
 		}
-		p.nextToken()
+	} else if p.token.Type == TokenEOF {
+		return p.errorf("unexpected end of input: missing '}' to close object")
+	} else if p.token.Type != TokenRightBrace {
+		return p.errorf("expected ',' or '}', found %s", describeToken(p.token))
+	}
+	return nil
+}
+
+func (p *Parser) closeObject(c *container) (interface{}, error) {
+	if comments := p.takeComments(); comments != nil && c.ordered != nil {
+		c.ordered.SetTrailingComments(comments)
+	}
+	if p.opts.PreserveComments {
+		p.commentOwner = c.outer
+	}
+	if p.handler != nil {
+		if err := p.handler.OnObjectEnd(); err != nil {
+			return nil, err
+		}
+	}
+	p.leave()
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	return c.value(), nil
+}
+
+// nextKeyToken reads a token that should be an object key, letting the lexer
+// intern it with Options.KeyInterner.
+func (p *Parser) nextKeyToken() error {
+	p.lexer.keyInterner = p.opts.KeyInterner
+	err := p.nextToken()
+	p.lexer.keyInterner = nil
+	return err
+}
+
+// takeComments returns the comments read since they were last taken.
+func (p *Parser) takeComments() []string {
+	comments := p.lexer.comments
+	p.lexer.comments = nil
+	return comments
+}
 
-		value := p.parseValue()
-		obj[key] = value
+// keepValueComments gives the comments read before the current token, which
+// lies inside a member's value but is not an object's brace, to that member.
+func (p *Parser) keepValueComments() {
+	comments := p.takeComments()
+	if comments != nil && p.commentOwner != nil {
+		p.commentOwner.valueComments = append(p.commentOwner.valueComments, comments...)
+	}
+}
 
-		if p.token.Type == TokenComma {
-			p.nextToken()
-		} else if p.token.Type != TokenRightBrace {
-			panic("Expected ',' or '}' in object")
+// isKey reports whether token can name an object member. With
+// AllowUnquotedKeys, bare identifiers and the words true, false and null are
+// accepted as well as strings.
+func (p *Parser) isKey(token Token) bool {
+	switch token.Type {
+	case TokenString:
+		return true
+	case TokenIdentifier, TokenBoolean, TokenNull:
+		return p.opts.AllowUnquotedKeys
+	}
+	return false
+}
+
+func (p *Parser) parseArray() (interface{}, error) {
+	c, err := p.openArray()
+	if c == nil {
+		return nil, err
+	}
+	if err != nil {
+		if err := p.resync(err, p.depth, len(p.path), TokenRightBracket); err != nil {
+			return nil, err
 		}
 	}
+	for !p.abandoned && p.token.Type != TokenRightBracket {
+		depth, pathLen := p.depth, len(p.path)
+		if err := p.parseElement(c); err != nil {
+			if err := p.resync(err, depth, pathLen, TokenRightBracket); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.abandoned {
+		p.path = p.path[:len(p.path)-1]
+		p.leave()
+		return c.value(), nil
+	}
+	return p.closeArray(c)
+}
+
+func (p *Parser) parseElement(c *container) error {
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	p.appendElement(c, value)
+	if p.abandoned {
+		return nil
+	}
+	return p.arraySeparator()
+}
 
-	p.nextToken()
-	return obj
+// openArray consumes the '[' of an array and leaves the parser on its first
+// element or its closing bracket. Like openObject, it returns the container
+// along with any error reading that token.
+func (p *Parser) openArray() (*container, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	p.keepValueComments()
+	c := &container{isArray: true}
+	if !p.discard {
+		c.arr = []interface{}{}
+	}
+	if p.stats != nil {
+		p.stats.NumArrays++
+	}
+	if p.handler != nil {
+		if err := p.handler.OnArrayStart(); err != nil {
+			return nil, err
+		}
+	}
+	p.path = append(p.path, pathSegment{isIndex: true})
+	if err := p.nextToken(); err != nil {
+		return c, err
+	}
+	if p.token.Type == TokenEOF {
+		return c, p.errorf("unexpected end of input: missing ']' to close array")
+	}
+	return c, nil
 }
 
-func (p *Parser) parseArray() []interface{} {
-	arr := []interface{}{}
-	p.nextToken()
+func (p *Parser) appendElement(c *container, value interface{}) {
+	if p.discard {
+		return
+	}
+	if len(c.arr) == cap(c.arr) {
+		// append grows large slices by only 1.25x, which copies a huge
+		// array many times over; doubling keeps the total copying to
+		// about twice the final size.
+		c.arr = slices.Grow(c.arr, len(c.arr)+1)
+	}
+	c.arr = append(c.arr, value)
+}
 
-	for p.token.Type != TokenRightBracket {
-		arr = append(arr, p.parseValue())
+// arraySeparator consumes the comma after an element, if there is one, and
+// leaves the parser on the next element or the closing bracket.
+func (p *Parser) arraySeparator() error {
+	if p.token.Type == TokenComma {
+		p.path[len(p.path)-1].index++
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+		if p.token.Type == TokenRightBracket && !p.opts.AllowTrailingCommas {
+			return p.errorf("trailing comma before ']'")
+		}
+		if p.token.Type == TokenEOF {
+			return p.errorf("unexpected end of input: missing ']' to close array")
+		}
+	} else if p.token.Type == TokenEOF {
+		return p.errorf("unexpected end of input: missing ']' to close array")
+	} else if p.token.Type != TokenRightBracket {
+		return p.errorf("expected ',' or ']', found %s", describeToken(p.token))
+	}
+	return nil
+}
 
-		if p.token.Type == TokenComma {
-			p.nextToken()
-		} else if p.token.Type != TokenRightBracket {
-			panic("Expected ',' or ']' in array")
+func (p *Parser) closeArray(c *container) (interface{}, error) {
+	p.keepValueComments()
+	p.path = p.path[:len(p.path)-1]
+	if p.handler != nil {
+		if err := p.handler.OnArrayEnd(); err != nil {
+			return nil, err
 		}
 	}
+	p.leave()
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	return c.value(), nil
+}
 
-	p.nextToken()
-	return arr
+// atRawDepth reports whether the current value should be returned as a
+// RawMessage because of Options.RawDepth.
+func (p *Parser) atRawDepth() bool {
+	return p.opts.RawDepth > 0 && p.depth == p.opts.RawDepth && !p.discard
 }
 
-func (p *Parser) parseValue() interface{} {
+func (p *Parser) parseValue() (interface{}, error) {
+	if p.atRawDepth() {
+		return p.parseRaw()
+	}
+
+	var val interface{}
 	switch p.token.Type {
 	case TokenString:
-		val := p.token.Value
-		p.nextToken()
-		return val
+		if !p.discard || p.handler != nil {
+			val = p.token.Value
+		}
 	case TokenNumber:
-		val, _ := strconv.ParseFloat(p.token.Value, 64)
-		p.nextToken()
-		return val
+		if !p.discard || p.handler != nil {
+			n, err := p.convertNumber()
+			if err != nil {
+				return nil, err
+			}
+			val = n
+		}
 	case TokenBoolean:
-		val := p.token.Value == "true"
-		p.nextToken()
-		return val
+		val = p.token.Value == "true"
 	case TokenNull:
-		p.nextToken()
-		return nil
+		val = p.opts.NullValue
 	case TokenLeftBrace:
 		return p.parseObject()
 	case TokenLeftBracket:
 		return p.parseArray()
 	default:
-		panic("Unexpected token: " + p.token.Value)
+		return nil, p.errorf("unexpected %s while expecting a value", describeToken(p.token))
 	}
+
+	if p.stats != nil {
+		p.stats.count(p.token.Type)
+	}
+	if p.handler != nil {
+		if err := p.handler.OnValue(val); err != nil {
+			return nil, err
+		}
+	}
+	if p.opts.TagTypes && !p.discard {
+		val = TypedValue{JSONType: scalarTypeNames[p.token.Type], Value: val}
+	}
+	p.keepValueComments()
+	return val, p.nextToken()
+}
+
+func (p *Parser) convertNumber() (interface{}, error) {
+	if p.opts.NumberConverter != nil {
+		val, err := p.opts.NumberConverter(p.token.Value)
+		if err != nil {
+			return nil, p.errorf("invalid number %s: %v", p.token.Value, err)
+		}
+		return val, nil
+	}
+	if p.opts.UseDecimalStrings {
+		return Decimal(p.token.Value), nil
+	}
+	if p.opts.UseNumber {
+		return Number(p.token.Value), nil
+	}
+	val, err := parseNumber(p.token.Value)
+	if p.opts.BigIntFallback {
+		if _, ok := val.(int64); (!ok || err != nil) && isIntegerLiteral(p.token.Value) {
+			if n, ok := new(big.Int).SetString(p.token.Value, 10); ok {
+				return n, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, p.errorf("invalid number %s", p.token.Value)
+	}
+	return val, nil
+}
+
+func describeToken(token Token) string {
+	switch token.Type {
+	case TokenEOF:
+		return "end of input"
+	case TokenString:
+		return fmt.Sprintf("string %q", token.Value)
+	case TokenNumber:
+		return "number " + token.Value
+	case TokenBoolean, TokenNull:
+		return token.Value
+	case TokenIdentifier:
+		return "identifier " + token.Value
+	}
+	return "'" + token.Value + "'"
+}
+
+// parseNumber returns an int64 for integer literals that fit in 64 bits and a
+// float64 for anything with a fraction or exponent. Integers outside the int64
+// range fall back to float64, and "-0" becomes int64(0).
+func parseNumber(text string) (interface{}, error) {
+	if !strings.ContainsAny(text, ".eE") {
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// isIntegerLiteral reports whether text is a number with no fraction or exponent.
+func isIntegerLiteral(text string) bool {
+	digits := strings.TrimPrefix(text, "-")
+	return digits != "" && isDigit(rune(digits[0])) && !strings.ContainsAny(digits, ".eE")
 }
 
 func main() {
@@ -237,9 +1708,10 @@ func main() {
 		"address": { "continent": "Asia", "Location": "South Asia" }
 	}`
 
-	lexer := NewLexer(jsonInput)
-	parser := NewParser(lexer)
-
-	parsedData := parser.parseJSON()
+	parsedData, err := Parse(jsonInput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	fmt.Println(parsedData)
 }
@@ -0,0 +1,1815 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// sampleDocument is the document main parses, with a key that needs escaping
+// in pointers and paths.
+const sampleDocument = `{
+	"name": "nepal",
+	"age": 0,
+	"country": true,
+	"districts": ["Kathmandu", "Lalitpur"],
+	"address": { "continent": "Asia", "Location": "South Asia" },
+	"a/b~c": 1
+}`
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	s, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func mustParse(t *testing.T, input string) interface{} {
+	t.Helper()
+	v, err := Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   string
+		err    string
+	}{
+		{LastWins, `{"a":2,"b":3}`, ""},
+		{FirstWins, `{"a":1,"b":3}`, ""},
+		{DuplicateKeyError, "", `duplicate key "a"`},
+	}
+	for _, tt := range tests {
+		for _, ordered := range []bool{false, true} {
+			v, err := ParseWithOptions(`{"a":1,"b":3,"a":2}`, Options{DuplicateKeys: tt.policy, OrderedObjects: ordered})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("policy %d: error = %v, want %q", tt.policy, err, tt.err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("policy %d: %v", tt.policy, err)
+			}
+			if got, _ := Marshal(v); got != tt.want {
+				t.Errorf("policy %d, ordered %v: got %s, want %s", tt.policy, ordered, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestDuplicateKeyErrorWithoutStoring(t *testing.T) {
+	opts := Options{DuplicateKeys: DuplicateKeyError}
+	input := `{"x":{"a":1,"a":2}}`
+
+	opts.RawDepth = 1
+	if _, err := ParseWithOptions(input, opts); err == nil {
+		t.Error("RawDepth: duplicate key accepted")
+	}
+
+	parser, err := NewParserWithOptions(NewLexer(input), Options{DuplicateKeys: DuplicateKeyError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.SkipValue(); err == nil {
+		t.Error("SkipValue: duplicate key accepted")
+	}
+}
+
+func TestParseReturnsErrors(t *testing.T) {
+	inputs := []string{
+		``,
+		`{`,
+		`[1,`,
+		`{"a" 1}`,
+		`{1:2}`,
+		`[1 2]`,
+		`nul`,
+		`tru`,
+		`@`,
+		`}`,
+	}
+	for _, input := range inputs {
+		v, err := Parse(input)
+		if err == nil {
+			t.Errorf("Parse(%q) = %v, want error", input, v)
+			continue
+		}
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("Parse(%q) error %T, want *ParseError", input, err)
+		}
+	}
+}
+
+func TestReadStringEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`"\""`, `"`, ""},
+		{`"\\"`, `\`, ""},
+		{`"\/"`, `/`, ""},
+		{`"\b"`, "\b", ""},
+		{`"\f"`, "\f", ""},
+		{`"\n"`, "\n", ""},
+		{`"\r"`, "\r", ""},
+		{`"\t"`, "\t", ""},
+		{`"a\tb\nc"`, "a\tb\nc", ""},
+		{`"\x41"`, "", `invalid escape sequence \x`},
+		{`"\'"`, "", `invalid escape sequence \'`},
+		{`"abc\`, "", "unexpected end of input in escape sequence"},
+		{"\"\\\x00\"", "", `invalid escape sequence: '\' followed by U+0000`},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if v != tt.want {
+			t.Errorf("Parse(%s) = %q, want %q", tt.input, v, tt.want)
+		}
+	}
+}
+
+func TestReadUnicodeEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`"\u0041"`, "A", ""},
+		{`"caf\u00e9"`, "café", ""},
+		{`"\u00E9"`, "é", ""},
+		{`"\u65e5\u672c"`, "日本", ""},
+		{`"\uD83D\uDE00"`, "\U0001F600", ""},
+		{`"x\ud83d\ude00y"`, "x\U0001F600y", ""},
+		{`"\uDE00"`, "", `unexpected low surrogate \uDE00`},
+		{`"\uD83D"`, "", `missing low surrogate after \uD83D`},
+		{`"\uD83Dx"`, "", `missing low surrogate after \uD83D`},
+		{`"\uD83D\n"`, "", `missing low surrogate after \uD83D`},
+		{`"\uD83D\u0041"`, "", `invalid surrogate pair \uD83D\u0041`},
+		{`"\u12G4"`, "", `invalid hex digit 'G' in \u escape`},
+		{`"\u12`, "", `unexpected end of input in \u escape`},
+		{"\"\\u12\x004\"", "", `invalid hex digit '\x00' in \u escape`},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if v != tt.want {
+			t.Errorf("Parse(%s) = %q, want %q", tt.input, v, tt.want)
+		}
+	}
+}
+
+func TestMultibyteStrings(t *testing.T) {
+	tests := []string{
+		`"café"`,
+		`"日本語"`,
+		`{"clé":"naïve","😀":["日本語"]}`,
+	}
+	for _, input := range tests {
+		v, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", input, err)
+			continue
+		}
+		if got, err := Marshal(v); err != nil || got != input {
+			t.Errorf("round trip of %s = %s, %v", input, got, err)
+		}
+	}
+}
+
+func TestMultibyteColumns(t *testing.T) {
+	_, err := Parse(`["日本語" x]`)
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error = %v, want *ParseError", err)
+	}
+	if perr.Col != 8 || perr.Offset != 13 {
+		t.Errorf("error at column %d, offset %d; want column 8, offset 13", perr.Col, perr.Offset)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input     string
+		line, col int
+	}{
+		{"{\n  \"a\": 1,\n  \"b\" 2\n}", 3, 7},
+		{"[1,\n 2,\n ]", 3, 2},
+		{`{"a":tru}`, 1, 6},
+		{"[\n\t\"x\n\"]", 2, 4},
+		{`{"a":1 "b":2}`, 1, 8},
+		{"\n\n   @", 3, 4},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want *ParseError", tt.input, err)
+			continue
+		}
+		if perr.Line != tt.line || perr.Col != tt.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d", tt.input, perr.Line, perr.Col, tt.line, tt.col)
+		}
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	_, err := Parse("{\"a\":1\n\n  \"b\":2}")
+	want := `parse error at line 3, column 3: expected ',' or '}', found string "b"`
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+func TestParseNumberTypes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"0", int64(0)},
+		{"-0", int64(0)},
+		{"42", int64(42)},
+		{"-17", int64(-17)},
+		{"9223372036854775807", int64(9223372036854775807)},
+		{"-9223372036854775808", int64(-9223372036854775808)},
+		{"9223372036854775808", float64(9223372036854775808)},
+		{"1.5", 1.5},
+		{"1.0", 1.0},
+		{"1e3", 1000.0},
+		{"-2.5E-1", -0.25},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if v != tt.want {
+			t.Errorf("Parse(%s) = %#v (%T), want %#v (%T)", tt.input, v, v, tt.want, tt.want)
+		}
+	}
+}
+
+func TestReadNumberSyntax(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"0", ""},
+		{"-0.0e-0", ""},
+		{"1e+10", ""},
+		{"01", "leading zeros not allowed"},
+		{"-01", "leading zeros not allowed"},
+		{"1.", "expected digit after '.'"},
+		{".5", "missing digit before '.'"},
+		{"1e", "expected digit in exponent"},
+		{"1e+", "expected digit in exponent"},
+		{"-", "expected digit"},
+		{"--5", "expected digit"},
+		{"+1", "leading '+' not allowed"},
+		{"1.2.3", "missing digit before '.'"},
+		{"-1-2.3.4", "unexpected trailing content"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestExponentNumbers(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`1e10`, `10000000000`},
+		{`1.5E-3`, `0.0015`},
+		{`2e+2`, `200`},
+		{`[1e2,"after",true]`, `[100,"after",true]`},
+		{`{"a":-3E1,"b":1}`, `{"a":-30,"b":1}`},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+			continue
+		}
+		if got, _ := Marshal(v); got != tt.want {
+			t.Errorf("Parse(%s) marshals as %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTrailingContent(t *testing.T) {
+	tests := []struct {
+		input string
+		col   int
+	}{
+		{`{}}`, 3},
+		{`[1,2]]`, 6},
+		{`[1,2] [3,4]`, 7},
+		{`{"a":1}{"b":2}`, 8},
+		{`{} garbage`, 4},
+		{`1 2`, 3},
+		{`null,`, 5},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%s) error = %v, want *ParseError", tt.input, err)
+		} else if perr.Col != tt.col {
+			t.Errorf("Parse(%s) error at column %d, want %d", tt.input, perr.Col, tt.col)
+		}
+	}
+	for _, input := range []string{"{} ", "[1]\n\t", " 3 "} {
+		if _, err := Parse(input); err != nil {
+			t.Errorf("Parse(%q): %v", input, err)
+		}
+	}
+}
+
+func TestTopLevelScalars(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`42`, int64(42)},
+		{`"x"`, "x"},
+		{`true`, true},
+		{`false`, false},
+		{`null`, nil},
+		{" \n-1.5 ", -1.5},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+		} else if v != tt.want {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.input, v, tt.want)
+		}
+	}
+	for _, input := range []string{"", "  \n "} {
+		_, err := Parse(input)
+		if err == nil || !strings.Contains(err.Error(), "empty document") {
+			t.Errorf("Parse(%q) error = %v, want an empty document error", input, err)
+		}
+	}
+}
+
+func TestUnterminatedStrings(t *testing.T) {
+	tests := []struct {
+		input     string
+		err       string
+		line, col int
+	}{
+		{`"abc`, "unterminated string literal", 1, 1},
+		{`{"a": "unterminated`, "unterminated string literal", 1, 7},
+		{`["ok", "cut`, "unterminated string literal", 1, 8},
+		{`{"key`, "unterminated string literal", 1, 2},
+		{"\"a\nb\"", "unescaped control character U+000A", 1, 3},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok || !strings.Contains(perr.Msg, tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+			continue
+		}
+		if perr.Line != tt.line || perr.Col != tt.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d", tt.input, perr.Line, perr.Col, tt.line, tt.col)
+		}
+	}
+}
+
+func TestControlCharacters(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{"\"a\tb\"", "", "unescaped control character U+0009"},
+		{"\"a\nb\"", "", "unescaped control character U+000A"},
+		{"\"\x00\"", "", "unescaped control character U+0000"},
+		{"\"\x1f\"", "", "unescaped control character U+001F"},
+		{`"a\tb"`, "a\tb", ""},
+		{`"a\nb"`, "a\nb", ""},
+		{"\"\x7f\"", "\x7f", ""},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+		} else if v != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.input, v, tt.want)
+		}
+	}
+}
+
+func TestLexerReader(t *testing.T) {
+	// Multibyte runes and long strings straddle the reader's chunk boundaries.
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < 2000; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`{"clé":"日本語😀","n":-12.5e3}`)
+	}
+	sb.WriteString(`,"` + strings.Repeat("é", 5000) + `"]`)
+	input := sb.String()
+	want, err := Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readers := map[string]func() io.Reader{
+		"strings.Reader": func() io.Reader { return strings.NewReader(input) },
+		"one byte":       func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+		"pipe": func() io.Reader {
+			r, w := io.Pipe()
+			go func() {
+				io.Copy(w, strings.NewReader(input))
+				w.Close()
+			}()
+			return r
+		},
+	}
+	for name, reader := range readers {
+		t.Run(name, func(t *testing.T) {
+			parser, err := NewParser(NewLexerReader(reader()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parser.Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got, want) {
+				t.Error("parsing from the reader gave a different value")
+			}
+		})
+	}
+}
+
+func TestLexerReaderError(t *testing.T) {
+	_, err := NewParser(NewLexerReader(iotest.ErrReader(io.ErrUnexpectedEOF)))
+	if err == nil || !strings.Contains(err.Error(), io.ErrUnexpectedEOF.Error()) {
+		t.Errorf("error = %v, want the reader's error", err)
+	}
+}
+
+func TestDuplicateKeyEscaped(t *testing.T) {
+	tests := []struct {
+		input     string
+		err       string
+		line, col int
+	}{
+		{`{"a":1,"\u0061":2}`, `duplicate key "a"`, 1, 8},
+		{"{\"a\\/b\":1,\n\"a/b\":2}", `duplicate key "a/b"`, 2, 1},
+		{`{"x":{"k":1},"y":{"k":1,"b":2,"k":3}}`, `duplicate key "k"`, 1, 31},
+		{`{"a":1,"A":2,"a ":3}`, "", 0, 0},
+		{`[{"a":1},{"a":2}]`, "", 0, 0},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.input); err != nil {
+			t.Errorf("last wins: Parse(%s): %v", tt.input, err)
+		}
+
+		_, err := ParseWithOptions(tt.input, Options{DuplicateKeys: DuplicateKeyError})
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("strict: Parse(%s): %v", tt.input, err)
+			}
+			continue
+		}
+		perr, ok := err.(*ParseError)
+		if !ok || !strings.Contains(perr.Msg, tt.err) {
+			t.Errorf("strict: Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		} else if perr.Line != tt.line || perr.Col != tt.col {
+			t.Errorf("strict: Parse(%s) error at %d:%d, want %d:%d", tt.input, perr.Line, perr.Col, tt.line, tt.col)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	nested := func(open, close string, n int) string {
+		return strings.Repeat(open, n) + strings.Repeat(close, n)
+	}
+	tests := []struct {
+		input string
+		opts  Options
+		err   string
+	}{
+		{nested("[", "]", 1000000), Options{}, "maximum nesting depth of 10000 exceeded"},
+		{strings.Repeat(`{"a":`, 1000000), Options{}, "maximum nesting depth of 10000 exceeded"},
+		{nested("[", "]", 10000), Options{}, ""},
+		{nested("[", "]", 3), Options{MaxDepth: 3}, ""},
+		{nested("[", "]", 4), Options{MaxDepth: 3}, "maximum nesting depth of 3 exceeded"},
+		{`[{"a":[1]}]`, Options{MaxDepth: 2}, "maximum nesting depth of 2 exceeded"},
+	}
+	for _, tt := range tests {
+		_, err := ParseWithOptions(tt.input, tt.opts)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Parse(%.20s...): %v", tt.input, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Parse(%.20s...) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestAllowComments(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{"// leading\n{\"a\": 1}", `{"a":1}`, ""},
+		{"{/* before key */ \"a\": 1}", `{"a":1}`, ""},
+		{"{\"a\" /* before colon */ : /* before value */ 1}", `{"a":1}`, ""},
+		{"{\"a\": 1 // after value\n, \"b\": 2}", `{"a":1,"b":2}`, ""},
+		{"[1, /* inside */ 2 // end of line\n]", `[1,2]`, ""},
+		{"{\"a\": 1 /* ** / */}", `{"a":1}`, ""},
+		{"[1] /* trailing */ // trailing\n", `[1]`, ""},
+		{`{"s": "// not a comment"}`, `{"s":"// not a comment"}`, ""},
+		{`{"a":1} /* open`, "", "unterminated block comment"},
+		{`[1,/*/]`, "", "unterminated block comment"},
+		{`[1 / 2]`, "", "unexpected character '/'"},
+		{"//only", "", "empty document"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, Options{AllowComments: true})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got, _ := Marshal(v); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	if _, err := Parse("[1, /* c */ 2]"); err == nil || !strings.Contains(err.Error(), "unexpected character '/'") {
+		t.Errorf("comment without AllowComments: error = %v", err)
+	}
+}
+
+func TestAllowTrailingCommas(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`{"a":1,}`, `{"a":1}`, "trailing comma before '}'"},
+		{`[1,2,]`, `[1,2]`, "trailing comma before ']'"},
+		{`{"a":[1,{"b":2,},],}`, `{"a":[1,{"b":2}]}`, "trailing comma before '}'"},
+		{`[[],{},]`, `[[],{}]`, "trailing comma before ']'"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, Options{AllowTrailingCommas: true})
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if got, _ := Marshal(v); got != tt.want {
+			t.Errorf("Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+		if _, err := Parse(tt.input); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("strict Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+	for _, input := range []string{`[,]`, `[,1]`, `[1,,]`, `{,}`, `{"a":1,,}`} {
+		if _, err := ParseWithOptions(input, Options{AllowTrailingCommas: true}); err == nil {
+			t.Errorf("Parse(%s) succeeded", input)
+		}
+	}
+}
+
+func TestMissingValue(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+		col   int
+	}{
+		{`{"a":}`, `missing value for key "a"`, 6},
+		{`{"a":,"b":1}`, `missing value for key "a"`, 6},
+		{`{"a":1,"b":}`, `missing value for key "b"`, 12},
+		{`{"a":1,"b":`, `missing value for key "b"`, 12},
+		{`{"x":{"y":}}`, `missing value for key "y"`, 11},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok || perr.Msg != tt.err {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		} else if perr.Col != tt.col {
+			t.Errorf("Parse(%s) error at column %d, want %d", tt.input, perr.Col, tt.col)
+		}
+	}
+}
+
+func TestByteOrderMark(t *testing.T) {
+	const bom = "\xEF\xBB\xBF"
+	input := `{"a":[1,"é"],"b":null}`
+	want, err := Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsers := map[string]func(string) (interface{}, error){
+		"Parse":      Parse,
+		"ParseBytes": func(s string) (interface{}, error) { return ParseBytes([]byte(s)) },
+		"reader": func(s string) (interface{}, error) {
+			parser, err := NewParser(NewLexerReader(iotest.OneByteReader(strings.NewReader(s))))
+			if err != nil {
+				return nil, err
+			}
+			return parser.Parse()
+		},
+	}
+	for name, parse := range parsers {
+		got, err := parse(bom + input)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	if !Valid(bom + input) {
+		t.Error("Valid rejected a leading byte order mark")
+	}
+
+	for _, input := range []string{bom + bom + "1", "[" + bom + "1]"} {
+		if _, err := Parse(input); err == nil || !strings.Contains(err.Error(), "unexpected character '\\ufeff'") {
+			t.Errorf("Parse(%q) error = %v, want the mark rejected", input, err)
+		}
+	}
+}
+
+func TestUnexpectedTokenMessages(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`[}`, "parse error at $[0], line 1, column 2: unexpected '}' while expecting a value"},
+		{`{"a":[1,}]}`, "parse error at $.a[1], line 1, column 9: unexpected '}' while expecting a value"},
+		{`[:]`, "parse error at $[0], line 1, column 2: unexpected ':' while expecting a value"},
+		{`:`, "parse error at line 1, column 1: unexpected ':' at start of document"},
+		{`}`, "parse error at line 1, column 1: unexpected '}' at start of document"},
+		{`{"a"::1}`, `parse error at $.a, line 1, column 6: unexpected second ':' after key "a"`},
+		{`[1:2]`, "parse error at $[0], line 1, column 3: expected ',' or ']', found ':'"},
+		{`{"a" 1}`, `parse error at $.a, line 1, column 6: expected ':' after key "a", found number 1`},
+		{`[1,`, "parse error at $[1], line 1, column 4: unexpected end of input: missing ']' to close array"},
+		{`{"a"`, `parse error at $.a, line 1, column 5: expected ':' after key "a", found end of input`},
+		{``, "parse error at line 1, column 1: unexpected end of input: empty document"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%s) error = %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+// cancelAfter is a context that is cancelled once Err has been called n
+// times, so a test can cancel a parse partway through.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseContext(t *testing.T) {
+	input := manyObjects(10000)
+	ctx := &cancelAfter{Context: context.Background(), n: 5}
+	v, err := ParseContext(ctx, input)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseContext = %v, want context.Canceled", err)
+	}
+	if v != nil {
+		t.Errorf("ParseContext returned a value with the error")
+	}
+	if ctx.n > 0 {
+		t.Error("the context was not checked during the parse")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(cancelled, `[1]`); err != context.Canceled {
+		t.Errorf("ParseContext with a cancelled context = %v", err)
+	}
+	if _, err := ParseContext(context.Background(), input); err != nil {
+		t.Errorf("ParseContext: %v", err)
+	}
+}
+
+func TestErrorOffsets(t *testing.T) {
+	tests := []struct {
+		input string
+		at    string
+	}{
+		{`{"a": tru}`, "tru"},
+		{`[1, 2 3]`, "3"},
+		{`{"é": @}`, "@"},
+		{`{"a":"x\q"}`, `\q`},
+		{`  [01]`, "01"},
+		{`["abc`, `"abc`},
+		{"[1,\n  ]", "]"},
+		{`{"a":1}  x`, "x"},
+		{`{"a":1,"a":2}`, `"a":2`},
+	}
+	for _, tt := range tests {
+		_, err := ParseWithOptions(tt.input, Options{DuplicateKeys: DuplicateKeyError})
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want *ParseError", tt.input, err)
+			continue
+		}
+		if want := strings.LastIndex(tt.input, tt.at); perr.Offset != want {
+			t.Errorf("Parse(%q) error at offset %d, want %d", tt.input, perr.Offset, want)
+		}
+	}
+}
+
+func TestParseErrorFields(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ParseError
+	}{
+		{"{\n  \"a\": [1, 2,, 3]\n}", ParseError{Msg: "unexpected ',' while expecting a value", Line: 2, Col: 14, Offset: 15, Path: "$.a[2]"}},
+		{`["a` + "\x01" + `"]`, ParseError{Msg: "unescaped control character U+0001 in string literal", Line: 1, Col: 4, Offset: 3, Path: "$[0]"}},
+		{`nul`, ParseError{Msg: `invalid literal "nul"`, Line: 1, Col: 1, Offset: 0}},
+	}
+	for _, tt := range tests {
+		_, parseErr := Parse(tt.input)
+		_, bytesErr := ParseBytes([]byte(tt.input))
+		_, decodeErr := NewDecoder(strings.NewReader(tt.input)).Decode()
+		for _, err := range []error{parseErr, bytesErr, ValidateError(tt.input), decodeErr} {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Errorf("Parse(%q) error %T, want *ParseError", tt.input, err)
+				continue
+			}
+			if *perr != tt.want {
+				t.Errorf("Parse(%q) error = %+v, want %+v", tt.input, *perr, tt.want)
+			}
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	input := []byte(`{"a":[1,"é",true],"b":{"c":null}}`)
+	got, err := ParseBytes(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Parse(string(input))
+	if !Equal(got, want) {
+		t.Errorf("ParseBytes = %v, want %v", got, want)
+	}
+	// Values must not share the caller's slice.
+	copy(input, `{"x"`)
+	if !Equal(got, want) {
+		t.Error("changing the input changed the parsed value")
+	}
+	if _, err := ParseBytes([]byte(`[1,]`)); err == nil || !strings.Contains(err.Error(), "trailing comma") {
+		t.Errorf("ParseBytes error = %v", err)
+	}
+
+	large := []byte(manyObjects(100))
+	// A string conversion and the lexer's copy of it.
+	bytes := testing.AllocsPerRun(10, func() { ParseBytes(large) })
+	str := testing.AllocsPerRun(10, func() { Parse(string(large)) })
+	if bytes > str-2 {
+		t.Errorf("ParseBytes allocates %v times, Parse(string(b)) %v; want at least 2 fewer", bytes, str)
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	input := []byte(manyObjects(10000))
+	b.Run("ParseBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseBytes(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Parse(string(input)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestTokens(t *testing.T) {
+	tokens, err := Tokens(`{"a":[1,true,null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Token{
+		{Type: TokenLeftBrace, Value: "{", Line: 1, Col: 1, Offset: 0},
+		{Type: TokenString, Value: "a", Line: 1, Col: 2, Offset: 1},
+		{Type: TokenColon, Value: ":", Line: 1, Col: 5, Offset: 4},
+		{Type: TokenLeftBracket, Value: "[", Line: 1, Col: 6, Offset: 5},
+		{Type: TokenNumber, Value: "1", Line: 1, Col: 7, Offset: 6},
+		{Type: TokenComma, Value: ",", Line: 1, Col: 8, Offset: 7},
+		{Type: TokenBoolean, Value: "true", Line: 1, Col: 9, Offset: 8},
+		{Type: TokenComma, Value: ",", Line: 1, Col: 13, Offset: 12},
+		{Type: TokenNull, Value: "null", Line: 1, Col: 14, Offset: 13},
+		{Type: TokenRightBracket, Value: "]", Line: 1, Col: 18, Offset: 17},
+		{Type: TokenRightBrace, Value: "}", Line: 1, Col: 19, Offset: 18},
+		{Type: TokenEOF, Line: 1, Col: 20, Offset: 19},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d: %v", len(tokens), len(want), tokens)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+
+	tokens, err = Tokens("[1, @]")
+	if err == nil || len(tokens) != 3 {
+		t.Errorf("Tokens with a bad character = %v, %v; want 3 tokens and an error", tokens, err)
+	}
+	if TokenRightBrace.String() != "RightBrace" || TokenType(99).String() != "TokenType(99)" {
+		t.Errorf("TokenType names: %s, %s", TokenRightBrace, TokenType(99))
+	}
+}
+
+func TestMinusInsideNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`[1-2]`, "expected ',' or ']', found number -2"},
+		{`{"a":1-2}`, "expected ',' or '}', found number -2"},
+		{`[1e-2-3]`, "expected ',' or ']', found number -3"},
+		{`1-`, `invalid number "-": expected digit`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+	if v, err := Parse(`[-1,1e-2,-0.5E-1]`); err != nil || mustMarshal(t, v) != `[-1,0.01,-0.05]` {
+		t.Errorf("Parse of valid minus signs = %v, %v", v, err)
+	}
+}
+
+func TestAllowSpecialFloats(t *testing.T) {
+	tests := []struct {
+		input string
+		check func(float64) bool
+	}{
+		{"NaN", math.IsNaN},
+		{"Infinity", func(f float64) bool { return math.IsInf(f, 1) }},
+		{"-Infinity", func(f float64) bool { return math.IsInf(f, -1) }},
+	}
+	for _, tt := range tests {
+		for _, input := range []string{tt.input, "[" + tt.input + "]"} {
+			v, err := ParseWithOptions(input, Options{AllowSpecialFloats: true})
+			if err != nil {
+				t.Errorf("Parse(%s): %v", input, err)
+				continue
+			}
+			if arr, ok := v.([]interface{}); ok {
+				v = arr[0]
+			}
+			if f, ok := v.(float64); !ok || !tt.check(f) {
+				t.Errorf("Parse(%s) = %#v", input, v)
+			}
+
+			_, err = Parse(input)
+			want := tt.input + " is not valid JSON unless AllowSpecialFloats is set"
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("strict Parse(%s) error = %v, want %q", input, err, want)
+			}
+		}
+	}
+	for _, input := range []string{"nan", "+Infinity", "-NaN", "Infinit"} {
+		if _, err := ParseWithOptions(input, Options{AllowSpecialFloats: true}); err == nil {
+			t.Errorf("Parse(%s) succeeded", input)
+		}
+	}
+	if _, err := Marshal(math.NaN()); err == nil {
+		t.Error("Marshal(NaN) succeeded")
+	}
+}
+
+func TestLeadingZeros(t *testing.T) {
+	for _, input := range []string{"00", "0123", "-01", "[0, 012]", `{"a":-00.5}`} {
+		_, err := Parse(input)
+		if err == nil || !strings.Contains(err.Error(), "invalid number: leading zeros not allowed") {
+			t.Errorf("Parse(%s) error = %v", input, err)
+		}
+	}
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"0", int64(0)},
+		{"-0", int64(0)},
+		{"0.5", 0.5},
+		{"-0.5", -0.5},
+		{"0e5", 0.0},
+		{"10", int64(10)},
+	}
+	for _, tt := range tests {
+		if v, err := Parse(tt.input); err != nil || v != tt.want {
+			t.Errorf("Parse(%s) = %#v, %v; want %#v", tt.input, v, err, tt.want)
+		}
+	}
+}
+
+func TestAllowSingleQuotes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{'a':'b'}`, `{"a":"b"}`},
+		{`{'a':"b", "c":'d'}`, `{"a":"b","c":"d"}`},
+		{`['it\'s', "say \"hi\"", 'q"q', "q'q"]`, `["it's","say \"hi\"","q\"q","q'q"]`},
+		{`['a\nb\tA']`, `["a\nb\tA"]`},
+		{`["\'"]`, `["'"]`},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, Options{AllowSingleQuotes: true})
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+			continue
+		}
+		if got := mustMarshal(t, v); got != tt.want {
+			t.Errorf("Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := ParseWithOptions(`'unterminated`, Options{AllowSingleQuotes: true}); err == nil || !strings.Contains(err.Error(), "unterminated string literal") {
+		t.Errorf("unterminated single-quoted string: error = %v", err)
+	}
+	for _, input := range []string{`{'a':1}`, `['x']`, `["\'"]`} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("strict Parse(%s) succeeded", input)
+		}
+	}
+}
+
+func TestAllowUnquotedKeys(t *testing.T) {
+	opts := Options{AllowUnquotedKeys: true, OrderedObjects: true}
+	v, err := ParseWithOptions(`{name: "x", _id2: 1, $a: 2, é: 3, true: 4, null: 5, false: 6, "q": 7}`, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(t, v); got != `{"name":"x","_id2":1,"$a":2,"é":3,"true":4,"null":5,"false":6,"q":7}` {
+		t.Errorf("Parse = %s", got)
+	}
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{a b: 1}`, `expected ':' after key "a", found identifier b`},
+		{`{1a: 1}`, "expected string key in object"},
+		{`{a: b}`, "unexpected identifier b while expecting a value"},
+		{`[a]`, "unexpected identifier a while expecting a value"},
+		{`{-Ifoo: 1}`, `column 2: invalid number "-Ifoo"`},
+		{`[-Inf]`, `column 2: invalid number "-Inf"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseWithOptions(tt.input, opts)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+	if _, err := Parse(`{name: "x"}`); err == nil {
+		t.Error("strict Parse accepted an unquoted key")
+	}
+}
+
+func TestInvalidLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`tru`, `parse error at line 1, column 1: invalid literal "tru"`},
+		{`True`, `parse error at line 1, column 1: invalid literal "True" (did you mean "true"?)`},
+		{`nul`, `parse error at line 1, column 1: invalid literal "nul"`},
+		{`NULL`, `parse error at line 1, column 1: invalid literal "NULL" (did you mean "null"?)`},
+		{`yes`, `parse error at line 1, column 1: invalid literal "yes"`},
+		{`[falsey]`, `parse error at $[0], line 1, column 2: invalid literal "falsey"`},
+		{"{\n\"a\":nulll}", `parse error at $.a, line 2, column 5: invalid literal "nulll"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%s) error = %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	tests := []struct {
+		input     string
+		line, col int
+	}{
+		{"", 1, 1},
+		{" ", 1, 2},
+		{"\n\n\n", 4, 1},
+		{" \t\r\n ", 2, 2},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok || perr.Msg != "unexpected end of input: empty document" {
+			t.Errorf("Parse(%q) error = %v, want an empty document error", tt.input, err)
+			continue
+		}
+		if perr.Line != tt.line || perr.Col != tt.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d", tt.input, perr.Line, perr.Col, tt.line, tt.col)
+		}
+		if _, err := ParseBytes([]byte(tt.input)); err == nil {
+			t.Errorf("ParseBytes(%q) succeeded", tt.input)
+		}
+		if Valid(tt.input) {
+			t.Errorf("Valid(%q) = true", tt.input)
+		}
+	}
+}
+
+func TestStructuralTokenAtStart(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`,`, "unexpected ',' at start of document"},
+		{`, 1`, "unexpected ',' at start of document"},
+		{`:`, "unexpected ':' at start of document"},
+		{`:{}`, "unexpected ':' at start of document"},
+		{`]`, "unexpected ']' at start of document"},
+		{`}`, "unexpected '}' at start of document"},
+		{` ]]`, "unexpected ']' at start of document"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok || perr.Msg != tt.want {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestErrorPaths(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+	}{
+		{`{"address":{"districts":[1,2,01]}}`, "$.address.districts[2]"},
+		{`[[0,[1,{"a":x}]]]`, "$[0][1][1].a"},
+		{`{"a b":{"c-d":[tru]}}`, `$["a b"]["c-d"][0]`},
+		{`{"":[1.]}`, `$[""][0]`},
+		{`{"a":[{"b":1},{"c":}]}`, "$.a[1].c"},
+		{`[1,2`, "$[1]"},
+		{`{"a":1}x`, ""},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%s) error = %v, want *ParseError", tt.input, err)
+		} else if perr.Path != tt.path {
+			t.Errorf("Parse(%s) error path = %q, want %q", tt.input, perr.Path, tt.path)
+		}
+	}
+}
+
+func TestMaxNumberLength(t *testing.T) {
+	huge := strings.Repeat("9", 2000)
+	opts := Options{MaxNumberLength: 10, AllowHexNumbers: true}
+	for _, input := range []string{huge, "[" + huge + "]", "1." + huge, "1e" + huge, "0x" + strings.Repeat("F", 30), "12345678901"} {
+		_, err := ParseWithOptions(input, opts)
+		if err == nil || !strings.Contains(err.Error(), "invalid number: longer than the maximum of 10 characters") {
+			t.Errorf("Parse(%.20s...) error = %v", input, err)
+		}
+	}
+	for _, input := range []string{"1.5e10", "-123", "1234567890", "12345.6789", "[1,22,333]"} {
+		if _, err := ParseWithOptions(input, opts); err != nil {
+			t.Errorf("Parse(%s): %v", input, err)
+		}
+	}
+	if _, err := ParseWithOptions(huge, Options{UseNumber: true}); err != nil {
+		t.Errorf("Parse without a limit: %v", err)
+	}
+}
+
+func TestMaxTokensAndInputBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		err   string
+	}{
+		{`[1,2,3]`, Options{MaxTokens: 7}, ""},
+		{`[1,2,3,4]`, Options{MaxTokens: 7}, "document has more than the maximum of 7 tokens"},
+		{`{"a":[1,2]}`, Options{MaxTokens: 8}, "document has more than the maximum of 8 tokens"},
+		{`[1,2,3]`, Options{MaxInputBytes: 7}, ""},
+		{`[1,2,3] `, Options{MaxInputBytes: 7}, "input is larger than the maximum of 7 bytes"},
+		{`[1,2,3,4]`, Options{MaxInputBytes: 7}, "input is larger than the maximum of 7 bytes"},
+	}
+	for _, tt := range tests {
+		_, err := ParseWithOptions(tt.input, tt.opts)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Parse(%s, %+v): %v", tt.input, tt.opts, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Parse(%s, %+v) error = %v, want %q", tt.input, tt.opts, err, tt.err)
+		}
+	}
+}
+
+func TestMaxInputBytesFromReader(t *testing.T) {
+	input := manyObjects(1000)
+	opts := Options{MaxInputBytes: len(input) - 1}
+	parser, err := NewParserWithOptions(NewLexerReader(strings.NewReader(input)), opts)
+	if err == nil {
+		_, err = parser.Parse()
+	}
+	if err == nil || !strings.Contains(err.Error(), "input is larger than the maximum") {
+		t.Errorf("error = %v, want the input size limit", err)
+	}
+
+	opts.MaxInputBytes = len(input)
+	parser, err = NewParserWithOptions(NewLexerReader(strings.NewReader(input)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Parse(); err != nil {
+		t.Errorf("Parse at the limit: %v", err)
+	}
+}
+
+func TestUnicodeWhitespace(t *testing.T) {
+	tests := []string{
+		"[1,\u00a02]",
+		"{\u2003\"a\":1}",
+		"\u3000true",
+		"[1\u2028]",
+		"\v1",
+	}
+	for _, input := range tests {
+		_, err := Parse(input)
+		if err == nil || !strings.Contains(err.Error(), "unexpected character") {
+			t.Errorf("strict Parse(%q) error = %v", input, err)
+		}
+		if _, err := ParseWithOptions(input, Options{AllowUnicodeWhitespace: true}); err != nil {
+			t.Errorf("relaxed Parse(%q): %v", input, err)
+		}
+	}
+	if v, err := Parse(" \t\r\n[ \t\r\n1 \t\r\n] \t\r\n"); err != nil || mustMarshal(t, v) != "[1]" {
+		t.Errorf("JSON whitespace: %v, %v", v, err)
+	}
+	if v, err := ParseWithOptions("[\"\u00a0\"]", Options{}); err != nil || v.([]interface{})[0] != "\u00a0" {
+		t.Errorf("a non-breaking space inside a string: %q, %v", v, err)
+	}
+}
+
+func TestParseLargeArray(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 1000, 100000} {
+		v, err := Parse(numberArray(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		arr := v.([]interface{})
+		if len(arr) != n {
+			t.Fatalf("len = %d, want %d", len(arr), n)
+		}
+		// Doubling never leaves more than half of the backing array unused.
+		if n > 0 && cap(arr) > 2*n {
+			t.Errorf("n = %d: cap = %d, want at most %d", n, cap(arr), 2*n)
+		}
+		for _, i := range []int{0, n / 2, n - 1} {
+			if n > 0 && !Equal(arr[i], float64(i)) {
+				t.Errorf("n = %d: element %d = %v", n, i, arr[i])
+			}
+		}
+	}
+}
+
+func numberArray(n int) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(i))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func BenchmarkParseLargeArray(b *testing.B) {
+	input := numberArray(1000000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkArrayGrowth compares appendElement's doubling with plain append,
+// which is how parseArray grew its slice before.
+func BenchmarkArrayGrowth(b *testing.B) {
+	const n = 1000000
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			arr := []interface{}{}
+			for j := 0; j < n; j++ {
+				arr = append(arr, nil)
+			}
+		}
+	})
+	b.Run("appendElement", func(b *testing.B) {
+		b.ReportAllocs()
+		p := &Parser{}
+		for i := 0; i < b.N; i++ {
+			c := &container{isArray: true, arr: []interface{}{}}
+			for j := 0; j < n; j++ {
+				p.appendElement(c, nil)
+			}
+		}
+	})
+}
+
+func TestTokensAtEndOfInput(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Token
+	}{
+		{`3`, Token{Type: TokenNumber, Value: "3", Line: 1, Col: 1, Offset: 0}},
+		{`[1,2,3`, Token{Type: TokenNumber, Value: "3", Line: 1, Col: 6, Offset: 5}},
+		{`-12.5e3`, Token{Type: TokenNumber, Value: "-12.5e3", Line: 1, Col: 1, Offset: 0}},
+		{`true`, Token{Type: TokenBoolean, Value: "true", Line: 1, Col: 1, Offset: 0}},
+		{`[false`, Token{Type: TokenBoolean, Value: "false", Line: 1, Col: 2, Offset: 1}},
+		{"\nnull", Token{Type: TokenNull, Value: "null", Line: 2, Col: 1, Offset: 1}},
+	}
+	for _, tt := range tests {
+		for _, lexer := range []*Lexer{NewLexer(tt.input), NewLexerReader(iotest.OneByteReader(strings.NewReader(tt.input)))} {
+			var last Token
+			for {
+				tok, err := lexer.nextToken()
+				if err != nil {
+					t.Fatalf("%q: %v", tt.input, err)
+				}
+				if tok.Type == TokenEOF {
+					break
+				}
+				last = tok
+			}
+			if last != tt.want {
+				t.Errorf("last token of %q = %+v, want %+v", tt.input, last, tt.want)
+			}
+		}
+	}
+	for _, input := range []string{`3`, `true`, `false`, `null`} {
+		v, err := Parse(input)
+		if err != nil || mustMarshal(t, v) != input {
+			t.Errorf("Parse(%s) = %v, %v", input, v, err)
+		}
+	}
+}
+
+func TestCaseInsensitiveLiterals(t *testing.T) {
+	lenient := Options{CaseInsensitiveLiterals: true}
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`True`, `true`, `invalid literal "True" (did you mean "true"?)`},
+		{`[FALSE]`, `[false]`, `invalid literal "FALSE" (did you mean "false"?)`},
+		{`{"a":Null}`, `{"a":null}`, `invalid literal "Null" (did you mean "null"?)`},
+		{`tRuE`, `true`, `invalid literal "tRuE" (did you mean "true"?)`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("strict Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+		v, err := ParseWithOptions(tt.input, lenient)
+		if err != nil {
+			t.Errorf("lenient Parse(%s): %v", tt.input, err)
+		} else if got := mustMarshal(t, v); got != tt.want {
+			t.Errorf("lenient Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	// Only the case is relaxed, not the spelling.
+	for _, input := range []string{`nul`, `Nope`, `trUE1`} {
+		_, err := ParseWithOptions(input, lenient)
+		if err == nil || !strings.Contains(err.Error(), "invalid literal") {
+			t.Errorf("lenient Parse(%s) error = %v, want an invalid literal error", input, err)
+		}
+	}
+}
+
+func TestTokenTypeString(t *testing.T) {
+	tests := []struct {
+		typ  TokenType
+		want string
+	}{
+		{TokenString, "String"},
+		{TokenNumber, "Number"},
+		{TokenBoolean, "Boolean"},
+		{TokenNull, "Null"},
+		{TokenLeftBrace, "LeftBrace"},
+		{TokenRightBrace, "RightBrace"},
+		{TokenLeftBracket, "LeftBracket"},
+		{TokenRightBracket, "RightBracket"},
+		{TokenColon, "Colon"},
+		{TokenComma, "Comma"},
+		{TokenEOF, "EOF"},
+		{TokenIdentifier, "Identifier"},
+		{TokenType(-1), "TokenType(-1)"},
+		{TokenIdentifier + 1, "TokenType(12)"},
+	}
+	for _, tt := range tests {
+		if got := tt.typ.String(); got != tt.want {
+			t.Errorf("TokenType(%d).String() = %q, want %q", int(tt.typ), got, tt.want)
+		}
+	}
+}
+
+func TestJSON5Strings(t *testing.T) {
+	json5 := Options{JSON5Strings: true}
+	tests := []struct {
+		input  string
+		want   string
+		strict string
+	}{
+		{`"\x41"`, "A", `invalid escape sequence \x`},
+		{`"\x00\xe9"`, "\x00é", `invalid escape sequence \x`},
+		{`"a\vb"`, "a\vb", `invalid escape sequence \v`},
+		{`"\0"`, "\x00", `invalid escape sequence \0`},
+		{"\"line \\\ncontinued\"", "line continued", `'\' followed by U+000A`},
+		{"\"line \\\r\ncontinued\"", "line continued", `'\' followed by U+000D`},
+		{"\"line \\\xe2\x80\xa8continued\"", "line continued", `'\' followed by U+2028`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.strict) {
+			t.Errorf("strict Parse(%q) error = %v, want %q", tt.input, err, tt.strict)
+		}
+		if v, err := ParseWithOptions(tt.input, json5); err != nil || v != tt.want {
+			t.Errorf("JSON5 Parse(%q) = %q, %v; want %q", tt.input, v, err, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		input string
+		err   string
+	}{
+		{`"\x4"`, `invalid hex digit '"' in \x escape`},
+		{`"\xZZ"`, `invalid hex digit 'Z' in \x escape`},
+		{`"\01"`, `invalid escape sequence \01`},
+	}
+	for _, tt := range invalid {
+		_, err := ParseWithOptions(tt.input, json5)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("JSON5 Parse(%q) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestObjectColonErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a" "b"}`, `parse error at $.a, line 1, column 6: expected ':' after key "a", found string "b"`},
+		{`{"a" 1}`, `parse error at $.a, line 1, column 6: expected ':' after key "a", found number 1`},
+		{`{"a"}`, `parse error at $.a, line 1, column 5: expected ':' after key "a", found '}'`},
+		{`{"a"`, `parse error at $.a, line 1, column 5: expected ':' after key "a", found end of input`},
+		{`{"x":{"a" 1}}`, `parse error at $.x.a, line 1, column 11: expected ':' after key "a", found number 1`},
+		{`{"a"::1}`, `parse error at $.a, line 1, column 6: unexpected second ':' after key "a"`},
+		{`{"a":}`, `parse error at $.a, line 1, column 6: missing value for key "a"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%s) error = %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestParserReset(t *testing.T) {
+	parser, err := NewParserWithOptions(NewLexer(`[1, /* c */ {"a": "long value"}]`), Options{AllowComments: true, MaxDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`{"b" /* kept */ : 2}`, `{"b":2}`, ""},
+		{`"x"`, `"x"`, ""},
+		{"\n[[[1]]]", "", "parse error at $[0][0], line 2, column 3: maximum nesting depth of 2 exceeded"},
+		{`[1,2`, "", "parse error at $[1], line 1, column 5"},
+		{`true`, `true`, ""},
+		{`1 2`, "", "parse error at line 1, column 3: unexpected trailing content"},
+		{`{"a":[]}`, `{"a":[]}`, ""},
+	}
+	for _, tt := range tests {
+		// Inputs shorter than the last must not see its leftover bytes in
+		// the reused buffer.
+		if err := parser.Reset(tt.input); err != nil {
+			t.Fatalf("Reset(%q): %v", tt.input, err)
+		}
+		v, err := parser.Parse()
+		if tt.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) after Reset error = %v, want %s", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) after Reset: %v", tt.input, err)
+		} else if got := mustMarshal(t, v); got != tt.want {
+			t.Errorf("Parse(%q) after Reset = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	// A lexer over a caller's bytes copies on Reset rather than writing
+	// into them.
+	input := []byte(`[1]`)
+	lexer := NewLexerBytes(input)
+	lexer.Reset(`{}`)
+	if string(input) != `[1]` {
+		t.Errorf("Reset overwrote the caller's input: %s", input)
+	}
+	lexer = NewLexerReader(strings.NewReader(`[1, 2]`))
+	lexer.Reset(`null`)
+	if tok, err := lexer.nextToken(); err != nil || tok.Type != TokenNull {
+		t.Errorf("first token after Reset of a reader lexer = %v, %v", tok, err)
+	}
+}
+
+func BenchmarkParserReuse(b *testing.B) {
+	input := `{"id":1,"name":"item","tags":["a","b"],"active":true}`
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			parser, err := NewParser(NewLexer(input))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := parser.Parse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reset", func(b *testing.B) {
+		b.ReportAllocs()
+		parser, err := NewParser(NewLexer(input))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := 0; i < b.N; i++ {
+			if err := parser.Reset(input); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := parser.Parse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestJSON5Numbers(t *testing.T) {
+	json5 := Options{JSON5Numbers: true}
+	tests := []struct {
+		input  string
+		want   string
+		strict string
+	}{
+		{`+1`, `1`, "invalid number: leading '+' not allowed"},
+		{`[+1.5e2]`, `[150]`, "invalid number: leading '+' not allowed"},
+		{`.5`, `0.5`, "invalid number: missing digit before '.'"},
+		{`+.5`, `0.5`, "invalid number: leading '+' not allowed"},
+		{`-.5`, `-0.5`, "invalid number: missing digit before '.'"},
+		{`5.`, `5`, `invalid number "5.": expected digit after '.'`},
+		{`-5.`, `-5`, `invalid number "-5.": expected digit after '.'`},
+		{`5.e3`, `5000`, `invalid number "5.": expected digit after '.'`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.strict) {
+			t.Errorf("strict Parse(%s) error = %v, want %q", tt.input, err, tt.strict)
+		}
+		v, err := ParseWithOptions(tt.input, json5)
+		if err != nil {
+			t.Errorf("JSON5 Parse(%s): %v", tt.input, err)
+		} else if got := mustMarshal(t, v); got != tt.want {
+			t.Errorf("JSON5 Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	// A sign or point still needs a digit somewhere.
+	for _, input := range []string{`+`, `.`, `-.`, `++1`, `+-1`} {
+		if _, err := ParseWithOptions(input, json5); err == nil || !strings.Contains(err.Error(), "expected digit") {
+			t.Errorf("JSON5 Parse(%s) error = %v, want an expected digit error", input, err)
+		}
+	}
+}
+
+func TestNullValue(t *testing.T) {
+	type sentinel struct{}
+	null := &sentinel{}
+	input := `[null, {"a": null}, "null", []]`
+	tests := []struct {
+		opts Options
+		want interface{}
+	}{
+		{Options{}, nil},
+		{Options{NullValue: null}, null},
+		{Options{NullValue: "NULL"}, "NULL"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(input, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arr := v.([]interface{})
+		if arr[0] != tt.want {
+			t.Errorf("NullValue %v: element = %#v", tt.opts.NullValue, arr[0])
+		}
+		if a, ok := arr[1].(map[string]interface{})["a"]; !ok || a != tt.want {
+			t.Errorf("NullValue %v: member = %#v, %t", tt.opts.NullValue, a, ok)
+		}
+		if arr[2] != "null" {
+			t.Errorf("NullValue %v: the string \"null\" became %#v", tt.opts.NullValue, arr[2])
+		}
+	}
+	if v, err := ParseWithOptions(`null`, Options{NullValue: null}); err != nil || v != null {
+		t.Errorf("top-level null = %#v, %v", v, err)
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	line := `2024-01-02 INFO request {"user":"é","ids":[1,2]} took 5ms`
+	tests := []struct {
+		input  string
+		offset int
+		want   string
+		end    int
+	}{
+		{line, 24, `{"ids":[1,2],"user":"é"}`, 49},
+		{line, 23, `{"ids":[1,2],"user":"é"}`, 49},
+		{`x=42;`, 2, `42`, 4},
+		{`a "s" b`, 2, `"s"`, 5},
+		{`[1] [2]`, 0, `[1]`, 3},
+		{`[1] [2]`, 3, `[2]`, 7},
+	}
+	for _, tt := range tests {
+		v, end, err := ParseAt(tt.input, tt.offset)
+		if err != nil {
+			t.Errorf("ParseAt(%q, %d): %v", tt.input, tt.offset, err)
+			continue
+		}
+		if got := mustMarshal(t, v); got != tt.want || end != tt.end {
+			t.Errorf("ParseAt(%q, %d) = %s, %d; want %s, %d", tt.input, tt.offset, got, end, tt.want, tt.end)
+		}
+	}
+
+	errorTests := []struct {
+		input  string
+		offset int
+		err    string
+	}{
+		{"first\nsecond {\"a\": }", 13, `parse error at $.a, line 2, column 14: missing value for key "a"`},
+		{`x tru`, 2, `parse error at line 1, column 3: invalid literal "tru"`},
+		{`abc`, 3, `parse error at line 1, column 4: unexpected end of input: empty document`},
+		{`abc`, 4, `offset 4 out of range for input of length 3`},
+		{`abc`, -1, `offset -1 out of range for input of length 3`},
+	}
+	for _, tt := range errorTests {
+		_, _, err := ParseAt(tt.input, tt.offset)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("ParseAt(%q, %d) error = %v, want %s", tt.input, tt.offset, err, tt.err)
+		}
+	}
+	_, _, err := ParseAt("first\nsecond {\"a\": }", 13)
+	if perr, ok := err.(*ParseError); !ok || perr.Offset != 19 {
+		t.Errorf("ParseAt error offset = %#v, want 19", err)
+	}
+}
+
+// parseWithin parses input with each way of driving the parser and fails the
+// test if any of them has not returned within a second.
+func parseWithin(t *testing.T, input string) []error {
+	t.Helper()
+	parsers := []func() error{
+		func() error { _, err := Parse(input); return err },
+		func() error { _, err := ParseWithOptions(input, Options{Iterative: true}); return err },
+		func() error { _, err := ParseWithOptions(input, Options{CollectAllErrors: true}); return err },
+		func() error { return ValidateError(input) },
+		func() error {
+			parser, err := NewParser(NewLexerReader(strings.NewReader(input)))
+			if err == nil {
+				_, err = parser.Parse()
+			}
+			return err
+		},
+	}
+	errs := make(chan error, len(parsers))
+	for _, parse := range parsers {
+		go func() { errs <- parse() }()
+	}
+	var got []error
+	timeout := time.After(time.Second)
+	for range parsers {
+		select {
+		case err := <-errs:
+			got = append(got, err)
+		case <-timeout:
+			t.Fatalf("parsing %q did not terminate", input)
+		}
+	}
+	return got
+}
+
+func TestTruncatedObjects(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{`, "parse error at line 1, column 2: unexpected end of input: missing '}' to close object"},
+		{`{"a"`, `parse error at $.a, line 1, column 5: expected ':' after key "a", found end of input`},
+		{`{"a":`, `parse error at $.a, line 1, column 6: missing value for key "a"`},
+		{`{"a":1`, "parse error at line 1, column 7: unexpected end of input: missing '}' to close object"},
+		{`{"a":1,`, "parse error at line 1, column 8: unexpected end of input: missing '}' to close object"},
+		{`{"a":{"b":1}`, "parse error at line 1, column 13: unexpected end of input: missing '}' to close object"},
+	}
+	for _, tt := range tests {
+		for _, err := range parseWithin(t, tt.input) {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("parsing %q: error = %v, want %s", tt.input, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestTruncatedArrays(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`[`, "parse error at $[0], line 1, column 2: unexpected end of input: missing ']' to close array"},
+		{`[1`, "parse error at $[0], line 1, column 3: unexpected end of input: missing ']' to close array"},
+		{`[1,`, "parse error at $[1], line 1, column 4: unexpected end of input: missing ']' to close array"},
+		{`[1,2`, "parse error at $[1], line 1, column 5: unexpected end of input: missing ']' to close array"},
+		{`[[1,2]`, "parse error at $[0], line 1, column 7: unexpected end of input: missing ']' to close array"},
+		{`[{"a":1}`, "parse error at $[0], line 1, column 9: unexpected end of input: missing ']' to close array"},
+		{`{"a":{"b":[`, "parse error at $.a.b[0], line 1, column 12: unexpected end of input: missing ']' to close array"},
+	}
+	for _, tt := range tests {
+		for _, err := range parseWithin(t, tt.input) {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("parsing %q: error = %v, want %s", tt.input, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestMaxStringLength(t *testing.T) {
+	opts := Options{MaxStringLength: 4}
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`"abcd"`, ""},
+		{`"😀😀😀😀"`, ""},
+		{`"\u0041\u0042\n\t"`, ""},
+		{`["ok", {"key": ""}]`, ""},
+		{`"abcde"`, "parse error at line 1, column 1: string literal longer than the maximum of 4 characters"},
+		{`"ééééé"`, "parse error at line 1, column 1: string literal longer than the maximum of 4 characters"},
+		{`{"abcdef":1}`, "parse error at line 1, column 2: string literal longer than the maximum of 4 characters"},
+		{`["ok", "toolong"]`, "parse error at $[1], line 1, column 8: string literal longer than the maximum of 4 characters"},
+	}
+	for _, tt := range tests {
+		_, err := ParseWithOptions(tt.input, opts)
+		if tt.err == "" && err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("Parse(%s) error = %v, want %s", tt.input, err, tt.err)
+		}
+	}
+	if _, err := Parse(`"` + strings.Repeat("a", 100000) + `"`); err != nil {
+		t.Errorf("Parse of a long string without a limit: %v", err)
+	}
+
+	// The limit stops the scan rather than buffering the whole string.
+	parser, err := NewParserWithOptions(NewLexerReader(io.MultiReader(strings.NewReader(`"`), endlessReader{})), Options{MaxStringLength: 1000})
+	if err == nil {
+		_, err = parser.Parse()
+	}
+	if err == nil || !strings.Contains(err.Error(), "longer than the maximum of 1000 characters") {
+		t.Errorf("endless string error = %v", err)
+	}
+}
+
+// endlessReader is an unending run of the letter a.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func TestReplaceInvalidUnicode(t *testing.T) {
+	lenient := Options{ReplaceInvalidUnicode: true}
+	tests := []struct {
+		input  string
+		want   string
+		strict string
+	}{
+		{`"\uD83D"`, "\uFFFD", `missing low surrogate after \uD83D`},
+		{`"a\uD83Db"`, "a\uFFFDb", `missing low surrogate after \uD83D`},
+		{`"\uD83D\n"`, "\uFFFD\n", `missing low surrogate after \uD83D`},
+		{`"\uDE00"`, "\uFFFD", `unexpected low surrogate \uDE00`},
+		{`"\uD83D\u0041"`, "\uFFFDA", `invalid surrogate pair \uD83D\u0041`},
+		{`"\uD83D\uD83D\uDE00"`, "\uFFFD😀", `invalid surrogate pair \uD83D\uD83D`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.strict) {
+			t.Errorf("strict Parse(%s) error = %v, want %q", tt.input, err, tt.strict)
+		}
+		if v, err := ParseWithOptions(tt.input, lenient); err != nil || v != tt.want {
+			t.Errorf("lenient Parse(%s) = %q, %v; want %q", tt.input, v, err, tt.want)
+		}
+	}
+	// Valid pairs and malformed escapes are unaffected.
+	if v, err := ParseWithOptions(`"\uD83D\uDE00"`, lenient); err != nil || v != "😀" {
+		t.Errorf("lenient Parse of a valid pair = %q, %v", v, err)
+	}
+	if _, err := ParseWithOptions(`"\uD83D\uZZZZ"`, lenient); err == nil || !strings.Contains(err.Error(), `invalid hex digit 'Z' in \u escape`) {
+		t.Errorf("lenient Parse of a bad escape error = %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type MarshalOptions struct {
+	// Indent, when non-empty, puts each array element and object member on
+	// its own line, indented by one copy of Indent per nesting level.
+	Indent string
+	// EscapeNonASCII writes every non-ASCII rune as a \uXXXX escape.
+	EscapeNonASCII bool
+	// EscapeSlashes writes '/' as \/, so that "</" never appears in the
+	// output.
+	EscapeSlashes bool
+	// EscapeHTML writes <, > and & as \u003c, \u003e and \u0026 so the
+	// output can be embedded in HTML safely.
+	EscapeHTML bool
+	// KeyLess, when set, orders the keys of maps in place of the default
+	// sorted order. Keys it considers equal stay in sorted order. An
+	// OrderedMap keeps its own order regardless.
+	KeyLess func(a, b string) bool
+	// EmitComments writes the comments held by OrderedMaps, as kept by
+	// Options.PreserveComments, back out where they were found. The output is
+	// then JSONC rather than strict JSON.
+	EmitComments bool
+}
+
+// Marshal serializes a value built from maps, slices, strings, numbers,
+// booleans and nil back into JSON text. Map keys are written in sorted order
+// so the output is stable; an OrderedMap keeps its own key order.
+func Marshal(v interface{}) (string, error) {
+	return MarshalWithOptions(v, MarshalOptions{})
+}
+
+func MarshalIndent(v interface{}, indent string) (string, error) {
+	return MarshalWithOptions(v, MarshalOptions{Indent: indent})
+}
+
+func MarshalWithOptions(v interface{}, opts MarshalOptions) (string, error) {
+	var sb strings.Builder
+	e := &encoder{w: &sb, opts: opts}
+	if err := e.encode(v); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Encoder writes a sequence of JSON values to a stream, each followed by a
+// newline, so that a Decoder can read them back.
+type Encoder struct {
+	out io.Writer
+	w   *bufio.Writer
+	e   encoder
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return NewEncoderWithOptions(w, MarshalOptions{})
+}
+
+func NewEncoderWithOptions(w io.Writer, opts MarshalOptions) *Encoder {
+	bw := bufio.NewWriter(w)
+	return &Encoder{out: w, w: bw, e: encoder{w: bw, opts: opts}}
+}
+
+// Encode writes v as Marshal would, without building the whole text in
+// memory first. If v cannot be marshaled, the part of it already written to
+// the underlying writer stays there.
+func (enc *Encoder) Encode(v interface{}) error {
+	enc.e.depth = 0
+	if err := enc.e.encode(v); err != nil {
+		// Drop the partial value still held in the buffer.
+		enc.w.Reset(enc.out)
+		return err
+	}
+	enc.w.WriteByte('\n')
+	return enc.w.Flush()
+}
+
+// encodeWriter is the subset of strings.Builder and bufio.Writer the encoder
+// writes through.
+type encodeWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+	WriteRune(r rune) (int, error)
+}
+
+type encoder struct {
+	w     encodeWriter
+	opts  MarshalOptions
+	depth int
+}
+
+func (e *encoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.w.WriteString("null")
+	case bool:
+		e.w.WriteString(strconv.FormatBool(v))
+	case string:
+		e.writeString(v)
+	case int:
+		e.w.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		e.w.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		e.w.WriteString(strconv.FormatInt(v, 10))
+	case uint:
+		e.w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		e.w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		e.w.WriteString(strconv.FormatUint(v, 10))
+	case float32:
+		return e.writeFloat(float64(v), 32)
+	case float64:
+		return e.writeFloat(v, 64)
+	case Number:
+		if v == "" {
+			return errors.New("cannot marshal empty Number")
+		}
+		if !isJSONNumber(string(v)) {
+			return fmt.Errorf("cannot marshal Number %q: not a valid JSON number", string(v))
+		}
+		e.w.WriteString(string(v))
+	case Decimal:
+		if v == "" {
+			return errors.New("cannot marshal empty Decimal")
+		}
+		if !isJSONNumber(string(v)) {
+			return fmt.Errorf("cannot marshal Decimal %q: not a valid JSON number", string(v))
+		}
+		e.w.WriteString(string(v))
+	case TypedValue:
+		return e.encode(v.Value)
+	case *big.Int:
+		if v == nil {
+			e.w.WriteString("null")
+			return nil
+		}
+		e.w.WriteString(v.String())
+	case RawMessage:
+		if len(v) == 0 {
+			return errors.New("cannot marshal empty RawMessage")
+		}
+		e.w.Write(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if e.opts.KeyLess != nil {
+			sort.SliceStable(keys, func(i, j int) bool { return e.opts.KeyLess(keys[i], keys[j]) })
+		}
+		return e.writeObject(keys, func(key string) interface{} { return v[key] }, nil)
+	case *OrderedMap:
+		var comments *OrderedMap
+		if e.opts.EmitComments {
+			comments = v
+			e.writeValueComments(v.LeadingComments())
+		}
+		err := e.writeObject(v.Keys(), func(key string) interface{} {
+			value, _ := v.Get(key)
+			return value
+		}, comments)
+		if err == nil && comments != nil && e.depth == 0 {
+			e.writeComments(v.EndComments())
+		}
+		return err
+	case []interface{}:
+		e.w.WriteByte('[')
+		e.depth++
+		for i, elem := range v {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			e.newline()
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+		e.depth--
+		if len(v) > 0 {
+			e.newline()
+		}
+		e.w.WriteByte(']')
+	default:
+		return fmt.Errorf("cannot marshal value of type %T", v)
+	}
+	return nil
+}
+
+// writeObject writes the members named by keys. When comments is set, keys
+// are its keys in order and its comments are written with them.
+func (e *encoder) writeObject(keys []string, get func(string) interface{}, comments *OrderedMap) error {
+	e.w.WriteByte('{')
+	e.depth++
+	for i, key := range keys {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		if comments != nil {
+			e.writeComments(comments.pairs[i].Comments)
+		}
+		e.newline()
+		e.writeString(key)
+		e.w.WriteByte(':')
+		if e.opts.Indent != "" {
+			e.w.WriteByte(' ')
+		}
+		if comments != nil {
+			e.writeValueComments(comments.pairs[i].ValueComments)
+		}
+		if err := e.encode(get(key)); err != nil {
+			return err
+		}
+	}
+	var trailing []string
+	if comments != nil {
+		trailing = comments.TrailingComments()
+		e.writeComments(trailing)
+	}
+	e.depth--
+	if len(keys) > 0 || len(trailing) > 0 {
+		e.newline()
+	}
+	e.w.WriteByte('}')
+	return nil
+}
+
+// writeComments writes each comment on its own line. Without Indent, a line
+// comment is still ended with a newline so it does not swallow what follows.
+func (e *encoder) writeComments(comments []string) {
+	for _, comment := range comments {
+		e.newline()
+		e.w.WriteString(comment)
+		if e.opts.Indent == "" && strings.HasPrefix(comment, "//") {
+			e.w.WriteByte('\n')
+		}
+	}
+}
+
+// writeValueComments writes the comments that come before a value. A block
+// comment stays on the value's line, and a line comment ends its own.
+func (e *encoder) writeValueComments(comments []string) {
+	for _, comment := range comments {
+		e.w.WriteString(comment)
+		switch {
+		case !strings.HasPrefix(comment, "//"):
+			if e.opts.Indent != "" {
+				e.w.WriteByte(' ')
+			}
+		case e.opts.Indent == "":
+			e.w.WriteByte('\n')
+		default:
+			e.newline()
+		}
+	}
+}
+
+func (e *encoder) newline() {
+	if e.opts.Indent == "" {
+		return
+	}
+	e.w.WriteByte('\n')
+	for i := 0; i < e.depth; i++ {
+		e.w.WriteString(e.opts.Indent)
+	}
+}
+
+func (e *encoder) writeFloat(f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("cannot marshal unsupported float value %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	e.w.WriteString(strconv.FormatFloat(f, format, -1, bits))
+	return nil
+}
+
+func (e *encoder) writeString(s string) {
+	e.w.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+
+		switch {
+		case r == '"':
+			e.w.WriteString(`\"`)
+		case r == '\\':
+			e.w.WriteString(`\\`)
+		case r == '/' && e.opts.EscapeSlashes:
+			e.w.WriteString(`\/`)
+		case r == '\b':
+			e.w.WriteString(`\b`)
+		case r == '\f':
+			e.w.WriteString(`\f`)
+		case r == '\n':
+			e.w.WriteString(`\n`)
+		case r == '\r':
+			e.w.WriteString(`\r`)
+		case r == '\t':
+			e.w.WriteString(`\t`)
+		case r < 0x20:
+			e.writeUnicodeEscape(r)
+		case (r == '<' || r == '>' || r == '&') && e.opts.EscapeHTML:
+			e.writeUnicodeEscape(r)
+		case r == utf8.RuneError && width == 1:
+			e.w.WriteString(`\ufffd`)
+		case r >= utf8.RuneSelf && e.opts.EscapeNonASCII:
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				e.writeUnicodeEscape(r1)
+				e.writeUnicodeEscape(r2)
+			} else {
+				e.writeUnicodeEscape(r)
+			}
+		default:
+			e.w.WriteRune(r)
+		}
+	}
+	e.w.WriteByte('"')
+}
+
+func (e *encoder) writeUnicodeEscape(r rune) {
+	fmt.Fprintf(e.w, `\u%04x`, r)
+}
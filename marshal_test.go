@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	inputs := []string{
+		`{"a":[1,2.5,-3e-7,"x",true,false,null],"b":{"c":{}},"d":[]}`,
+		`"quote \" backslash \\ slash / controls \b\f\n\r\t \u0001"`,
+		`[{"日本":"é😀"},1e300,-0.5]`,
+		`{"\u00e9":"\ud83d\ude00"}`,
+	}
+	for _, input := range inputs {
+		want, err := Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Marshal(want)
+		if err != nil {
+			t.Errorf("Marshal(%s): %v", input, err)
+			continue
+		}
+		got, err := Parse(out)
+		if err != nil {
+			t.Errorf("reparsing %s: %v", out, err)
+		} else if !Equal(got, want) {
+			t.Errorf("round trip of %s gave %s", input, out)
+		}
+	}
+}
+
+func TestMarshalValues(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, `null`},
+		{true, `true`},
+		{int64(-42), `-42`},
+		{1.5, `1.5`},
+		{1e21, `1e+21`},
+		{map[string]interface{}{"b": 1, "a": []interface{}{}}, `{"a":[],"b":1}`},
+		{"a\"b\\c\n\x01", `"a\"b\\c\n\u0001"`},
+		{"</script>&", `"</script>&"`},
+		{"é", `"é"`},
+		{"\xff", `"\ufffd"`},
+	}
+	for _, tt := range tests {
+		if got, err := Marshal(tt.v); err != nil || got != tt.want {
+			t.Errorf("Marshal(%#v) = %s, %v; want %s", tt.v, got, err, tt.want)
+		}
+	}
+}
+
+func TestMarshalEscapes(t *testing.T) {
+	tests := []struct {
+		opts MarshalOptions
+		want string
+	}{
+		{MarshalOptions{}, `"é😀</a>&"`},
+		{MarshalOptions{EscapeNonASCII: true}, `"\u00e9\ud83d\ude00</a>&"`},
+		{MarshalOptions{EscapeSlashes: true}, `"é😀<\/a>&"`},
+		{MarshalOptions{EscapeHTML: true}, `"é😀\u003c/a\u003e\u0026"`},
+	}
+	for _, tt := range tests {
+		if got, err := MarshalWithOptions("é😀</a>&", tt.opts); err != nil || got != tt.want {
+			t.Errorf("MarshalWithOptions(%+v) = %s, %v; want %s", tt.opts, got, err, tt.want)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, v := range []interface{}{
+		struct{}{},
+		[]interface{}{make(chan int)},
+	} {
+		if out, err := Marshal(v); err == nil || !strings.Contains(err.Error(), "cannot marshal") {
+			t.Errorf("Marshal(%#v) = %s, %v; want an error", v, out, err)
+		}
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	v, err := Parse(`{"b":[1,{"c":null}],"a":{},"e":[],"d":{"x":"y"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		indent string
+		want   string
+	}{
+		{"  ", `{
+  "a": {},
+  "b": [
+    1,
+    {
+      "c": null
+    }
+  ],
+  "d": {
+    "x": "y"
+  },
+  "e": []
+}`},
+		{"    ", `{
+    "a": {},
+    "b": [
+        1,
+        {
+            "c": null
+        }
+    ],
+    "d": {
+        "x": "y"
+    },
+    "e": []
+}`},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 3; i++ {
+			got, err := MarshalIndent(v, tt.indent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("MarshalIndent(%q) =\n%s\nwant\n%s", tt.indent, got, tt.want)
+			}
+		}
+	}
+	if got, _ := MarshalIndent("x", "  "); got != `"x"` {
+		t.Errorf("MarshalIndent of a scalar = %s", got)
+	}
+}
+
+func TestEscapeSlashes(t *testing.T) {
+	for _, input := range []string{`"a/b"`, `"a\/b"`, `"a\u002fb"`} {
+		if v, err := Parse(input); err != nil || v != "a/b" {
+			t.Errorf("Parse(%s) = %v, %v; want a/b", input, v, err)
+		}
+	}
+	tests := []struct {
+		value interface{}
+		opts  MarshalOptions
+		want  string
+	}{
+		{"</script>", MarshalOptions{}, `"</script>"`},
+		{"</script>", MarshalOptions{EscapeSlashes: true}, `"<\/script>"`},
+		{map[string]interface{}{"a/b": "//"}, MarshalOptions{EscapeSlashes: true}, `{"a\/b":"\/\/"}`},
+		{[]interface{}{"/"}, MarshalOptions{EscapeSlashes: true, Indent: "  "}, "[\n  \"\\/\"\n]"},
+	}
+	for _, tt := range tests {
+		got, err := MarshalWithOptions(tt.value, tt.opts)
+		if err != nil || got != tt.want {
+			t.Errorf("MarshalWithOptions(%v, %+v) = %s, %v; want %s", tt.value, tt.opts, got, err, tt.want)
+			continue
+		}
+		if back := mustParse(t, got); !Equal(back, tt.value) {
+			t.Errorf("round trip of %s = %v, want %v", got, back, tt.value)
+		}
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		opts  MarshalOptions
+		want  string
+	}{
+		{"</script>", MarshalOptions{}, `"</script>"`},
+		{"</script>", MarshalOptions{EscapeHTML: true}, `"\u003c/script\u003e"`},
+		{"a && b", MarshalOptions{EscapeHTML: true}, `"a \u0026\u0026 b"`},
+		{"</script>", MarshalOptions{EscapeHTML: true, EscapeSlashes: true}, `"\u003c\/script\u003e"`},
+		{map[string]interface{}{"<k>": "&"}, MarshalOptions{EscapeHTML: true}, `{"\u003ck\u003e":"\u0026"}`},
+	}
+	for _, tt := range tests {
+		got, err := MarshalWithOptions(tt.value, tt.opts)
+		if err != nil || got != tt.want {
+			t.Errorf("MarshalWithOptions(%v, %+v) = %s, %v; want %s", tt.value, tt.opts, got, err, tt.want)
+			continue
+		}
+		if back := mustParse(t, got); !Equal(back, tt.value) {
+			t.Errorf("round trip of %s = %v, want %v", got, back, tt.value)
+		}
+	}
+}
+
+func TestMarshalKeyLess(t *testing.T) {
+	priority := map[string]int{"id": 0, "name": 1}
+	rank := func(key string) int {
+		if r, ok := priority[key]; ok {
+			return r
+		}
+		return len(priority)
+	}
+	tests := []struct {
+		name string
+		less func(a, b string) bool
+		want string
+	}{
+		{"default", nil, `{"Zeta":1,"alpha":2,"id":3,"name":4,"zeta":5}`},
+		{"reverse", func(a, b string) bool { return a > b }, `{"zeta":5,"name":4,"id":3,"alpha":2,"Zeta":1}`},
+		{"priority", func(a, b string) bool { return rank(a) < rank(b) }, `{"id":3,"name":4,"Zeta":1,"alpha":2,"zeta":5}`},
+		{"case-insensitive", func(a, b string) bool { return strings.ToLower(a) < strings.ToLower(b) }, `{"alpha":2,"id":3,"name":4,"Zeta":1,"zeta":5}`},
+	}
+	v := map[string]interface{}{"alpha": 2, "zeta": 5, "Zeta": 1, "id": 3, "name": 4}
+	for _, tt := range tests {
+		got, err := MarshalWithOptions(v, MarshalOptions{KeyLess: tt.less})
+		if err != nil || got != tt.want {
+			t.Errorf("%s: MarshalWithOptions = %s, %v; want %s", tt.name, got, err, tt.want)
+		}
+	}
+
+	nested := map[string]interface{}{"b": map[string]interface{}{"y": 1, "x": 2}, "a": []interface{}{map[string]interface{}{"d": 3, "c": 4}}}
+	reverse := MarshalOptions{KeyLess: func(a, b string) bool { return a > b }, Indent: " "}
+	want := "{\n \"b\": {\n  \"y\": 1,\n  \"x\": 2\n },\n \"a\": [\n  {\n   \"d\": 3,\n   \"c\": 4\n  }\n ]\n}"
+	if got, err := MarshalWithOptions(nested, reverse); err != nil || got != want {
+		t.Errorf("nested with reverse order = %s, %v; want %s", got, err, want)
+	}
+
+	ordered, err := ParseWithOptions(`{"b":1,"a":2}`, Options{OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := MarshalWithOptions(ordered, MarshalOptions{KeyLess: func(a, b string) bool { return a < b }}); err != nil || got != `{"b":1,"a":2}` {
+		t.Errorf("OrderedMap with KeyLess = %s, %v; want its own order", got, err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	values := []interface{}{
+		mustParse(t, sampleDocument),
+		[]interface{}{1.5, "é", nil, true},
+		"</script>",
+		map[string]interface{}{},
+	}
+	for _, opts := range []MarshalOptions{{}, {Indent: "  "}, {EscapeHTML: true, EscapeNonASCII: true}} {
+		var buf bytes.Buffer
+		enc := NewEncoderWithOptions(&buf, opts)
+		var want strings.Builder
+		for _, v := range values {
+			if err := enc.Encode(v); err != nil {
+				t.Fatalf("Encode(%v): %v", v, err)
+			}
+			s, err := MarshalWithOptions(v, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want.WriteString(s + "\n")
+			// Each value is flushed as soon as it is encoded.
+			if buf.String() != want.String() {
+				t.Fatalf("%+v: encoded %q, want %q", opts, buf.String(), want.String())
+			}
+		}
+
+		d := NewDecoder(&buf)
+		for i, v := range values {
+			got, err := d.Decode()
+			if err != nil || !Equal(got, v) {
+				t.Errorf("%+v: decoding value %d = %v, %v", opts, i, got, err)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode([]interface{}{1, make(chan int)}); err == nil {
+		t.Error("Encode of a channel succeeded")
+	}
+	if err := enc.Encode(1); err != nil || buf.String() != "1\n" {
+		t.Errorf("Encode after a failed value wrote %q, %v; want just 1", buf.String(), err)
+	}
+	if err := NewEncoder(failingWriter{}).Encode(1); err == nil {
+		t.Error("Encode to a failing writer succeeded")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type MergeOptions struct {
+	// AppendArrays concatenates arrays found at the same key instead of
+	// letting the override array replace the base one.
+	AppendArrays bool
+}
+
+// Merge deep-merges two parsed objects and returns the result without
+// modifying either input. Keys in override win; when both sides hold an
+// object at the same key the two are merged recursively, and any other value
+// in override, arrays included, replaces the base value. Both arguments must
+// be objects.
+func Merge(base, override interface{}) (interface{}, error) {
+	return MergeWithOptions(base, override, MergeOptions{})
+}
+
+func MergeWithOptions(base, override interface{}, opts MergeOptions) (interface{}, error) {
+	if !isObject(base) || !isObject(override) {
+		return nil, fmt.Errorf("cannot merge %s with %s: both values must be objects", jsonTypeName(base), jsonTypeName(override))
+	}
+	return mergeValues(base, override, opts), nil
+}
+
+func isObject(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, *OrderedMap:
+		return true
+	}
+	return false
+}
+
+func mergeValues(base, override interface{}, opts MergeOptions) interface{} {
+	if isObject(base) && isObject(override) {
+		return mergeObjects(base, override, opts)
+	}
+	if opts.AppendArrays {
+		if b, ok := base.([]interface{}); ok {
+			if o, ok := override.([]interface{}); ok {
+				merged := make([]interface{}, 0, len(b)+len(o))
+				return append(append(merged, b...), o...)
+			}
+		}
+	}
+	return override
+}
+
+// mergeObjects builds the merged object with the same representation as
+// base, keeping base's key order for an OrderedMap and appending new keys.
+func mergeObjects(base, override interface{}, opts MergeOptions) interface{} {
+	merge := func(get func(string) (interface{}, bool), set func(string, interface{})) {
+		forEachMember(override, func(key string, value interface{}) {
+			if existing, ok := get(key); ok {
+				value = mergeValues(existing, value, opts)
+			}
+			set(key, value)
+		})
+	}
+
+	if ordered, ok := base.(*OrderedMap); ok {
+		result := ordered.shallowCopy()
+		merge(result.Get, result.Set)
+		return result
+	}
+
+	m := base.(map[string]interface{})
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	merge(func(key string) (interface{}, bool) {
+		value, ok := result[key]
+		return value, ok
+	}, func(key string, value interface{}) {
+		result[key] = value
+	})
+	return result
+}
+
+// forEachMember calls fn for each member of obj in order: document order for
+// an OrderedMap and sorted key order for a plain map, so that keys new to an
+// OrderedMap base are appended deterministically.
+func forEachMember(obj interface{}, fn func(key string, value interface{})) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fn(key, obj[key])
+		}
+	case *OrderedMap:
+		for _, pair := range obj.Pairs() {
+			fn(pair.Key, pair.Value)
+		}
+	}
+}
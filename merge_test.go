@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name           string
+		base, override string
+		opts           MergeOptions
+		want           string
+	}{
+		{
+			name:     "nested",
+			base:     `{"db":{"host":"localhost","port":5432,"opts":{"ssl":false}},"debug":false}`,
+			override: `{"db":{"port":6543,"opts":{"ssl":true,"timeout":3}},"name":"app"}`,
+			want:     `{"db":{"host":"localhost","opts":{"ssl":true,"timeout":3},"port":6543},"debug":false,"name":"app"}`,
+		},
+		{
+			name:     "arrays replaced",
+			base:     `{"tags":["a","b"],"x":{"list":[1]}}`,
+			override: `{"tags":["c"],"x":{"list":[]}}`,
+			want:     `{"tags":["c"],"x":{"list":[]}}`,
+		},
+		{
+			name:     "arrays appended",
+			base:     `{"tags":["a","b"],"x":{"list":[1]}}`,
+			override: `{"tags":["c"],"x":{"list":[2]}}`,
+			opts:     MergeOptions{AppendArrays: true},
+			want:     `{"tags":["a","b","c"],"x":{"list":[1,2]}}`,
+		},
+		{
+			name:     "type changes",
+			base:     `{"a":{"b":1},"c":[1],"d":"s"}`,
+			override: `{"a":2,"c":{"e":1},"d":null}`,
+			want:     `{"a":2,"c":{"e":1},"d":null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := mustParse(t, tt.base)
+			override := mustParse(t, tt.override)
+			merged, err := MergeWithOptions(base, override, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := mustMarshal(t, merged); got != tt.want {
+				t.Errorf("Merge = %s, want %s", got, tt.want)
+			}
+			if !Equal(base, mustParse(t, tt.base)) {
+				t.Errorf("Merge changed its base to %s", mustMarshal(t, base))
+			}
+		})
+	}
+}
+
+func TestMergeOrdered(t *testing.T) {
+	opts := Options{OrderedObjects: true}
+	base, _ := ParseWithOptions(`{"z":1,"a":{"y":1,"x":2}}`, opts)
+	override, _ := ParseWithOptions(`{"b":3,"a":{"w":0,"y":9}}`, opts)
+	merged, err := Merge(base, override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(t, merged); got != `{"z":1,"a":{"y":9,"x":2,"w":0},"b":3}` {
+		t.Errorf("Merge = %s", got)
+	}
+}
+
+func TestMergeOrderedWithPlainOverride(t *testing.T) {
+	base, _ := ParseWithOptions(`{"z":1,"m":{"y":1}}`, Options{OrderedObjects: true})
+	override := map[string]interface{}{"d": 4, "b": 2, "c": 3, "a": 1, "m": map[string]interface{}{"x": 0, "w": 0}}
+	want := `{"z":1,"m":{"y":1,"w":0,"x":0},"a":1,"b":2,"c":3,"d":4}`
+	for i := 0; i < 20; i++ {
+		merged, err := Merge(base, override)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mustMarshal(t, merged); got != want {
+			t.Fatalf("Merge = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestMergeNonObjects(t *testing.T) {
+	for _, pair := range [][2]interface{}{
+		{[]interface{}{}, map[string]interface{}{}},
+		{map[string]interface{}{}, "x"},
+		{nil, nil},
+	} {
+		_, err := Merge(pair[0], pair[1])
+		if err == nil || !strings.Contains(err.Error(), "both values must be objects") {
+			t.Errorf("Merge(%v, %v) error = %v", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestMergeKeepsComments(t *testing.T) {
+	base, err := ParseWithOptions(jsoncSample, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := Merge(base, mustParse(t, `{"strict":false,"outDir":"dist"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MarshalWithOptions(merged, MarshalOptions{Indent: "  ", EmitComments: true})
+	want := strings.Replace(jsoncOutput, "/* inline */ true\n", "/* inline */ false,\n  \"outDir\": \"dist\"\n", 1)
+	if err != nil || got != want {
+		t.Errorf("merged JSONC = %s, %v; want\n%s", got, err, want)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Number holds the literal text of a JSON number, as returned by the parser
+// when Options.UseNumber is set.
+type Number string
+
+func (n Number) String() string {
+	return string(n)
+}
+
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Decimal holds the literal text of a JSON number, as returned by the parser
+// when Options.UseDecimalStrings is set. Unlike float64 it never rounds, and
+// Marshal writes it back exactly as it was read.
+type Decimal string
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+func (d Decimal) Int64() (int64, error) {
+	return strconv.ParseInt(string(d), 10, 64)
+}
+
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// Rat returns the exact value of d as a rational number, suitable for
+// arithmetic that must not lose precision.
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", string(d))
+	}
+	return r, nil
+}
+
+// isJSONNumber reports whether text follows the JSON number grammar, which
+// the text held by a Number or Decimal must for Marshal to write it. NaN and
+// Infinity, accepted with Options.AllowSpecialFloats, do not.
+func isJSONNumber(text string) bool {
+	i := 0
+	digits := func() int {
+		start := i
+		for i < len(text) && isDigit(rune(text[i])) {
+			i++
+		}
+		return i - start
+	}
+	if i < len(text) && text[i] == '-' {
+		i++
+	}
+	if i < len(text) && text[i] == '0' {
+		i++
+	} else if digits() == 0 {
+		return false
+	}
+	if i < len(text) && text[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+		i++
+		if i < len(text) && (text[i] == '+' || text[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(text)
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHexNumbersBecomeDecimal(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default", Options{}, `[255,-26,2.3611832414348226e+21]`},
+		{"UseNumber", Options{UseNumber: true}, `[255,-26,2361183241434822606847]`},
+		{"UseDecimalStrings", Options{UseDecimalStrings: true}, `[255,-26,2361183241434822606847]`},
+		{"BigIntFallback", Options{BigIntFallback: true}, `[255,-26,2361183241434822606847]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.AllowHexNumbers = true
+			v, err := ParseWithOptions(`[0xFF, -0x1a, 0x7FFFFFFFFFFFFFFFFF]`, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHexNumberSyntax(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{`0xFF`, `255`, ""},
+		{`0X1f`, `31`, ""},
+		{`-0x1A`, `-26`, ""},
+		{`[0x0, 0x10]`, `[0,16]`, ""},
+		{`0x`, "", `invalid number "0x": expected hex digit`},
+		{`0xG`, "", `invalid number "0x": expected hex digit`},
+		{`0x1.5`, "", "missing digit before '.'"},
+		{`00x1`, "", "leading zeros not allowed"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, Options{AllowHexNumbers: true})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%s) error = %v, want %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+		} else if got, _ := Marshal(v); got != tt.want {
+			t.Errorf("Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+
+		_, err = Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), "hexadecimal literals are not allowed unless AllowHexNumbers is set") {
+			t.Errorf("strict Parse(%s) error = %v", tt.input, err)
+		}
+	}
+}
+
+func TestNumberInt64(t *testing.T) {
+	v, err := ParseWithOptions(`0xFF`, Options{AllowHexNumbers: true, UseNumber: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.(Number).Int64(); err != nil || n != 255 {
+		t.Errorf("Number.Int64() = %d, %v", n, err)
+	}
+	tests := []struct {
+		text string
+		want int64
+		ok   bool
+	}{
+		{"42", 42, true},
+		{"-7", -7, true},
+		{"1.5", 0, false},
+		{"0xFF", 0, false},
+	}
+	for _, tt := range tests {
+		for _, got := range []func() (int64, error){Number(tt.text).Int64, Decimal(tt.text).Int64} {
+			n, err := got()
+			if (err == nil) != tt.ok || n != tt.want {
+				t.Errorf("Int64 of %q = %d, %v", tt.text, n, err)
+			}
+		}
+	}
+}
+
+func TestIsJSONNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"0", true},
+		{"-0", true},
+		{"12", true},
+		{"1.25", true},
+		{"-1e10", true},
+		{"2E-3", true},
+		{"", false},
+		{"-", false},
+		{"01", false},
+		{"1.", false},
+		{".5", false},
+		{"+1", false},
+		{"1e", false},
+		{"0xFF", false},
+		{"NaN", false},
+		{"Infinity", false},
+		{"-Infinity", false},
+	}
+	for _, tt := range tests {
+		if got := isJSONNumber(tt.text); got != tt.want {
+			t.Errorf("isJSONNumber(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalRejectsSpecialFloatText(t *testing.T) {
+	for _, opts := range []Options{
+		{AllowSpecialFloats: true, UseNumber: true},
+		{AllowSpecialFloats: true, UseDecimalStrings: true},
+	} {
+		for _, input := range []string{"NaN", "Infinity", "-Infinity"} {
+			v, err := ParseWithOptions(input, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out, err := Marshal(v); err == nil {
+				t.Errorf("Marshal(%#v) = %s, want an error", v, out)
+			}
+		}
+	}
+	if _, err := Marshal(Number("0xFF")); err == nil {
+		t.Error("Marshal(Number(\"0xFF\")) succeeded")
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	tests := []string{
+		`12345678901234567890`,
+		`0.1`,
+		`-1.50e+10`,
+		`[1,{"id":98765432109876543210}]`,
+	}
+	for _, input := range tests {
+		v, err := ParseWithOptions(input, Options{UseNumber: true})
+		if err != nil {
+			t.Errorf("Parse(%s): %v", input, err)
+			continue
+		}
+		if got, err := Marshal(v); err != nil || got != input {
+			t.Errorf("round trip of %s = %s, %v", input, got, err)
+		}
+	}
+
+	v, _ := ParseWithOptions(`0.1`, Options{UseNumber: true})
+	n, ok := v.(Number)
+	if !ok {
+		t.Fatalf("got %T, want Number", v)
+	}
+	if f, err := n.Float64(); err != nil || f != 0.1 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+	if _, err := Number("12345678901234567890").Int64(); err == nil {
+		t.Error("Int64() of a 20-digit number succeeded")
+	}
+}
+
+func TestUseDecimalStrings(t *testing.T) {
+	input := `{"price":0.1,"tax":0.2,"total":1.10,"big":123456789012345678901234567890.5,"exp":1e2}`
+	v, err := ParseWithOptions(input, Options{UseDecimalStrings: true, OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != input {
+		t.Errorf("round trip = %s, want %s", out, input)
+	}
+
+	obj := v.(*OrderedMap)
+	price, _ := obj.Get("price")
+	tax, _ := obj.Get("tax")
+	a, err := price.(Decimal).Rat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := tax.(Decimal).Rat()
+	if sum := a.Add(a, b).FloatString(20); strings.Contains(sum, "30000000000000004") || sum != "0.30000000000000000000" {
+		t.Errorf("0.1 + 0.2 = %s", sum)
+	}
+	if total, _ := obj.Get("total"); total.(Decimal).String() != "1.10" {
+		t.Errorf("total = %v, want the text 1.10", total)
+	}
+	if _, err := Decimal("abc").Rat(); err == nil {
+		t.Error("Rat() of invalid text succeeded")
+	}
+}
+
+func TestNumberConverter(t *testing.T) {
+	var seen []string
+	bigInts := func(raw string) (interface{}, error) {
+		seen = append(seen, raw)
+		if n, ok := new(big.Int).SetString(raw, 10); ok {
+			return n, nil
+		}
+		return strconv.ParseFloat(raw, 64)
+	}
+	v, err := ParseWithOptions(`[12345678901234567890123, -7, 0.5, "8", {"n": 1e2}]`, Options{NumberConverter: bigInts, UseNumber: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := v.([]interface{})
+	tests := []struct {
+		got  interface{}
+		want string
+	}{
+		{arr[0], "*big.Int 12345678901234567890123"},
+		{arr[1], "*big.Int -7"},
+		{arr[2], "float64 0.5"},
+		{arr[3], "string 8"},
+		{arr[4].(map[string]interface{})["n"], "float64 100"},
+	}
+	for i, tt := range tests {
+		if got := fmt.Sprintf("%T %v", tt.got, tt.got); got != tt.want {
+			t.Errorf("element %d = %s, want %s", i, got, tt.want)
+		}
+	}
+	if want := []string{"12345678901234567890123", "-7", "0.5", "1e2"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("converter called with %q, want %q", seen, want)
+	}
+
+	integersOnly := func(raw string) (interface{}, error) {
+		return strconv.ParseInt(raw, 10, 64)
+	}
+	_, err = ParseWithOptions(`[1, 2.5]`, Options{NumberConverter: integersOnly})
+	if err == nil || !strings.Contains(err.Error(), "$[1], line 1, column 5: invalid number 2.5: strconv.ParseInt") {
+		t.Errorf("converter error = %v", err)
+	}
+}
+
+func TestBigIntFallback(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`42`, "int64 42"},
+		{`-7`, "int64 -7"},
+		{`9223372036854775807`, "int64 9223372036854775807"},
+		{`9223372036854775808`, "*big.Int 9223372036854775808"},
+		{`-9223372036854775809`, "*big.Int -9223372036854775809"},
+		{`123456789012345678901234567890`, "*big.Int 123456789012345678901234567890"},
+		{`1.5`, "float64 1.5"},
+		{`1e2`, "float64 100"},
+		{`1e30`, "float64 1e+30"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, Options{BigIntFallback: true})
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.input, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T %v", v, v); got != tt.want {
+			t.Errorf("Parse(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+		if _, isBig := v.(*big.Int); isBig && mustMarshal(t, v) != tt.input {
+			t.Errorf("Marshal of %s = %s", tt.input, mustMarshal(t, v))
+		}
+	}
+	if v, _ := Parse(`9223372036854775808`); fmt.Sprintf("%T", v) != "float64" {
+		t.Errorf("without BigIntFallback an overflowing integer is %T, want float64", v)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+// Pair is one member of an OrderedMap. Comments holds the comments that
+// came before the key in the source, as written, when the document was
+// parsed with Options.PreserveComments. ValueComments holds those between
+// the key and the value, along with any inside an array value that no
+// object within it holds.
+type Pair struct {
+	Key           string
+	Value         interface{}
+	Comments      []string
+	ValueComments []string
+}
+
+// OrderedMap is a JSON object that keeps its keys in insertion order. The
+// parser produces it when Options.OrderedObjects is set.
+type OrderedMap struct {
+	pairs []Pair
+	index map[string]int
+	// leading holds the comments before the opening brace, trailing those
+	// after the last member and end those after the closing brace of the
+	// top-level object.
+	leading  []string
+	trailing []string
+	end      []string
+}
+
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{index: make(map[string]int)}
+}
+
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	i, ok := m.index[key]
+	if !ok {
+		return nil, false
+	}
+	return m.pairs[i].Value, true
+}
+
+func (m *OrderedMap) Has(key string) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Set updates the value of an existing key in place, or appends a new key at
+// the end.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if i, ok := m.index[key]; ok {
+		m.pairs[i].Value = value
+		return
+	}
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+	m.index[key] = len(m.pairs)
+	m.pairs = append(m.pairs, Pair{Key: key, Value: value})
+}
+
+// SetComments replaces the comments written before an existing key. It
+// reports whether the key was found.
+func (m *OrderedMap) SetComments(key string, comments []string) bool {
+	i, ok := m.index[key]
+	if ok {
+		m.pairs[i].Comments = comments
+	}
+	return ok
+}
+
+// SetValueComments replaces the comments written between an existing key
+// and its value. It reports whether the key was found.
+func (m *OrderedMap) SetValueComments(key string, comments []string) bool {
+	i, ok := m.index[key]
+	if ok {
+		m.pairs[i].ValueComments = comments
+	}
+	return ok
+}
+
+// LeadingComments returns the comments before the opening brace.
+func (m *OrderedMap) LeadingComments() []string {
+	return m.leading
+}
+
+func (m *OrderedMap) SetLeadingComments(comments []string) {
+	m.leading = comments
+}
+
+// TrailingComments returns the comments between the last member and the
+// closing brace.
+func (m *OrderedMap) TrailingComments() []string {
+	return m.trailing
+}
+
+func (m *OrderedMap) SetTrailingComments(comments []string) {
+	m.trailing = comments
+}
+
+// EndComments returns the comments after the closing brace, which the parser
+// keeps only for the top-level object.
+func (m *OrderedMap) EndComments() []string {
+	return m.end
+}
+
+func (m *OrderedMap) SetEndComments(comments []string) {
+	m.end = comments
+}
+
+// shallowCopy returns a copy of m that can be changed without affecting m
+// but shares its values and comments.
+func (m *OrderedMap) shallowCopy() *OrderedMap {
+	copied := &OrderedMap{
+		pairs:    append([]Pair(nil), m.pairs...),
+		index:    make(map[string]int, len(m.index)),
+		leading:  m.leading,
+		trailing: m.trailing,
+		end:      m.end,
+	}
+	for key, i := range m.index {
+		copied.index[key] = i
+	}
+	return copied
+}
+
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, len(m.pairs))
+	for i, pair := range m.pairs {
+		keys[i] = pair.Key
+	}
+	return keys
+}
+
+func (m *OrderedMap) Pairs() []Pair {
+	return m.pairs
+}
+
+func (m *OrderedMap) Len() int {
+	return len(m.pairs)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreserveCommentsRoundTrip(t *testing.T) {
+	src := `{
+  // listen address
+  "host": "0.0.0.0",
+  /* port to bind */
+  "port": 8080,
+  "tls": {
+    "cert": "a.pem"
+    // no key yet
+  }
+}`
+	v, err := ParseWithOptions(src, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := MarshalWithOptions(v, MarshalOptions{Indent: "  ", EmitComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != src {
+		t.Errorf("round trip changed the document:\n%s\nwant:\n%s", out, src)
+	}
+}
+
+// jsoncSample is a tsconfig-style file with comments around the root, inside
+// an array and before a value, and how it marshals with EmitComments.
+const (
+	jsoncSample = "// header\n{\"files\": [ // first\n \"a.ts\"], \"strict\": /* inline */ true}\n// footer\n"
+	jsoncOutput = `// header
+{
+  "files": // first
+  [
+    "a.ts"
+  ],
+  "strict": /* inline */ true
+}
+// footer`
+)
+
+func TestPreserveCommentsJSONC(t *testing.T) {
+	opts := MarshalOptions{Indent: "  ", EmitComments: true}
+	src := jsoncSample
+	for i := 0; i < 2; i++ {
+		v, err := ParseWithOptions(src, Options{PreserveComments: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := MarshalWithOptions(v, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != jsoncOutput {
+			t.Fatalf("pass %d: got\n%s\nwant\n%s", i, out, jsoncOutput)
+		}
+		// The output parses back to the same document and comments.
+		src = out
+	}
+}
+
+func TestPreserveCommentsPlacement(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"before key", "{/*a*/\"a\":1}", "{/*a*/\"a\":1}"},
+		{"before closing brace", "{\"a\":1/*end*/}", "{\"a\":1/*end*/}"},
+		{"line comment without indent", "{// a\n\"a\":1}", "{// a\n\"a\":1}"},
+		{"inside array", "{\"a\":[ // about elem\n1], \"b\":2}", "{\"a\":// about elem\n[1],\"b\":2}"},
+		{"inside nested arrays", "{\"a\":[[1 /*x*/],/*y*/[]]}", `{"a":/*x*//*y*/[[1],[]]}`},
+		{"before object in array", "{\"a\":[/*x*/{\"b\":1}]}", `{"a":[/*x*/{"b":1}]}`},
+		{"before root", "// header\n{\"a\":1}", "// header\n{\"a\":1}"},
+		{"after root", "{\"a\":1} // footer", "{\"a\":1}// footer\n"},
+		{"before value", "{\"a\": /*v*/ 1}", `{"a":/*v*/1}`},
+		{"before colon", "{\"a\" /*v*/ : 1}", `{"a":/*v*/1}`},
+		{"after value", "{\"a\": 1 /*v*/, \"b\": 2}", `{"a":1,/*v*/"b":2}`},
+		{"after last value", "{\"a\": 1 /*v*/}", `{"a":1/*v*/}`},
+		{"before nested object", "{\"a\": /*v*/ {\"b\": 2}}", `{"a":/*v*/{"b":2}}`},
+		{"after nested object", "{\"a\": {\"b\": 2} /*v*/, \"c\": 3}", `{"a":{"b":2},/*v*/"c":3}`},
+		{"top-level array", "/*x*/ [1, /*y*/ 2] /*z*/", `[1,2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseWithOptions(tt.input, Options{PreserveComments: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := MarshalWithOptions(v, MarshalOptions{EmitComments: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentsNotEmittedByDefault(t *testing.T) {
+	v, err := ParseWithOptions(`{/*a*/"a":1}`, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Marshal(v); got != `{"a":1}` {
+		t.Errorf("Marshal = %s", got)
+	}
+	if c := Clone(v).(*OrderedMap).Pairs()[0].Comments; len(c) != 1 || c[0] != "/*a*/" {
+		t.Errorf("Clone kept comments %q", c)
+	}
+}
+
+func TestOrderedObjectsKeepKeyOrder(t *testing.T) {
+	inputs := []string{
+		`{"z":1,"a":2,"m":3}`,
+		`{"b":{"y":1,"x":[{"d":1,"c":2}]},"a":null}`,
+		`[{"2":0,"10":0,"1":0}]`,
+	}
+	for _, input := range inputs {
+		v, err := ParseWithOptions(input, Options{OrderedObjects: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mustMarshal(t, v); got != input {
+			t.Errorf("round trip of %s = %s", input, got)
+		}
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	var nilMap *OrderedMap
+	if _, ok := nilMap.Get("a"); ok {
+		t.Error("Get on a nil map found a key")
+	}
+
+	m := NewOrderedMap()
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	if got := strings.Join(m.Keys(), ","); got != "b,a" {
+		t.Errorf("Keys() = %s, want b,a", got)
+	}
+	if v, ok := m.Get("b"); !ok || v != 3 {
+		t.Errorf("Get(b) = %v, %v", v, ok)
+	}
+	if !m.Has("a") || m.Has("c") || m.Len() != 2 {
+		t.Errorf("Has(a) = %v, Has(c) = %v, Len() = %d", m.Has("a"), m.Has("c"), m.Len())
+	}
+	if m.SetComments("c", []string{"// x"}) {
+		t.Error("SetComments on a missing key reported success")
+	}
+
+	var zero OrderedMap
+	zero.Set("k", true)
+	if got := mustMarshal(t, &zero); got != `{"k":true}` {
+		t.Errorf("zero OrderedMap marshals as %s", got)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		// Nesting.
+		`{}`,
+		`[]`,
+		`[[[[[]]]]]`,
+		`{"a":{"b":{"c":[1,{"d":[]}]}}}`,
+		`[{"a":[{"b":null}]},[true,false]]`,
+		// Escapes.
+		`"plain"`,
+		`"\" \\ \/ \b \f \n \r \t"`,
+		`"é中😀"`,
+		`"\uD800"`,
+		`"trailing backslash\"`,
+		"\"raw \xff byte\"",
+		// Numbers.
+		`0`,
+		`-0`,
+		`123456789`,
+		`-1.5e-10`,
+		`1E+300`,
+		`9223372036854775808`,
+		`01`,
+		`1.`,
+		`.5`,
+		`-`,
+		// Malformed structure.
+		`{"a":1,}`,
+		`[1,,2]`,
+		`{"a" 1}`,
+		`{"a":1} x`,
+		`[1, 2`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		v, err := Parse(input)
+		if err != nil {
+			if _, ok := err.(*ParseError); !ok {
+				t.Fatalf("Parse(%q) returned %T, want *ParseError: %v", input, err, err)
+			}
+			return
+		}
+		out, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(Parse(%q)): %v", input, err)
+		}
+		again, err := Parse(out)
+		if err != nil {
+			t.Fatalf("Parse(%q), marshaled from %q: %v", out, input, err)
+		}
+		if !Equal(v, again) {
+			t.Fatalf("round trip of %q changed %#v to %#v", input, v, again)
+		}
+	})
+}
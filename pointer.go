@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetPointer resolves an RFC 6901 JSON Pointer such as "/address/continent"
+// or "/districts/0" against a parsed value. The empty pointer refers to the
+// whole document.
+func GetPointer(data interface{}, pointer string) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := data
+	at := ""
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q: key %q not found at %s", pointer, token, rootPointer(at))
+			}
+			current = value
+		case *OrderedMap:
+			value, ok := node.Get(token)
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q: key %q not found at %s", pointer, token, rootPointer(at))
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("json pointer %q: %v at %s", pointer, err, rootPointer(at))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("json pointer %q: cannot index into %s at %s", pointer, jsonTypeName(current), rootPointer(at))
+		}
+		at += "/" + pointerEscaper.Replace(token)
+	}
+	return current, nil
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func rootPointer(pointer string) string {
+	if pointer == "" {
+		return "the root"
+	}
+	return pointer
+}
+
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("json pointer %q: must be empty or start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("json pointer %q: invalid escape in %q", pointer, token)
+			}
+		}
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= length {
+		return 0, fmt.Errorf("array index %s out of range", token)
+	}
+	return index, nil
+}
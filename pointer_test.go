@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetPointer(t *testing.T) {
+	data, err := Parse(sampleDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ordered, err := ParseWithOptions(sampleDocument, Options{OrderedObjects: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"/address/continent", `"Asia"`},
+		{"/districts/0", `"Kathmandu"`},
+		{"/districts/1", `"Lalitpur"`},
+		{"/age", `0`},
+		{"/a~1b~0c", `1`},
+	}
+	for _, tt := range tests {
+		for _, doc := range []interface{}{data, ordered} {
+			v, err := GetPointer(doc, tt.pointer)
+			if err != nil {
+				t.Errorf("GetPointer(%q): %v", tt.pointer, err)
+				continue
+			}
+			if got, _ := Marshal(v); got != tt.want {
+				t.Errorf("GetPointer(%q) = %s, want %s", tt.pointer, got, tt.want)
+			}
+		}
+	}
+	for _, doc := range []interface{}{data, ordered} {
+		if v, err := GetPointer(doc, ""); err != nil || !Equal(v, doc) {
+			t.Errorf("the empty pointer gave %v, %v; want the whole document", v, err)
+		}
+	}
+}
+
+func TestGetPointerErrors(t *testing.T) {
+	data, err := Parse(sampleDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pointer string
+		err     string
+	}{
+		{"/missing", `key "missing" not found at the root`},
+		{"/address/zip", `key "zip" not found at /address`},
+		{"/districts/2", "array index 2 out of range at /districts"},
+		{"/districts/-", `invalid array index "-"`},
+		{"/districts/01", `invalid array index "01"`},
+		{"/age/x", "cannot index into number at /age"},
+		{"address", "must be empty or start with '/'"},
+		{"/a~2", `invalid escape in "a~2"`},
+	}
+	for _, tt := range tests {
+		_, err := GetPointer(data, tt.pointer)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("GetPointer(%q) error = %v, want %q", tt.pointer, err, tt.err)
+		}
+	}
+}
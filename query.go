@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Query evaluates a JSONPath expression against a parsed value and returns
+// every match. Only a subset of JSONPath is supported: the root $, member
+// access with .key, array indexing with [n] and the array wildcard [*]. A path
+// that matches nothing returns an empty slice.
+func Query(data interface{}, path string) ([]interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []interface{}{data}
+	for _, seg := range segments {
+		next := []interface{}{}
+		for _, node := range matches {
+			switch node := node.(type) {
+			case map[string]interface{}:
+				if value, ok := node[seg.key]; ok && !seg.isIndex && !seg.wildcard {
+					next = append(next, value)
+				}
+			case *OrderedMap:
+				if value, ok := node.Get(seg.key); ok && !seg.isIndex && !seg.wildcard {
+					next = append(next, value)
+				}
+			case []interface{}:
+				if seg.wildcard {
+					next = append(next, node...)
+				} else if seg.isIndex && seg.index < len(node) {
+					next = append(next, node[seg.index])
+				}
+			}
+		}
+		matches = next
+	}
+	return matches, nil
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("jsonpath %q: must start with '$'", path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("jsonpath %q: empty member name", path)
+			}
+			segments = append(segments, pathSegment{key: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q: missing ']'", path)
+			}
+			inner := rest[1:end]
+			if inner == "*" {
+				segments = append(segments, pathSegment{wildcard: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("jsonpath %q: invalid index %q", path, inner)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	data, err := Parse(sampleDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := Parse(`{"items":[{"id":1,"tags":["a"]},{"id":2,"tags":["b","c"]},{"name":"x"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		data interface{}
+		path string
+		want string
+	}{
+		{data, "$.districts[*]", `["Kathmandu","Lalitpur"]`},
+		{data, "$.address.continent", `["Asia"]`},
+		{data, "$.districts[1]", `["Lalitpur"]`},
+		{data, "$.districts[2]", `[]`},
+		{data, "$.missing.key", `[]`},
+		{data, "$.name[*]", `[]`},
+		{data, "$.districts.0", `[]`},
+		{data, "$", `[` + mustMarshal(t, data) + `]`},
+		{nested, "$.items[*].id", `[1,2]`},
+		{nested, "$.items[*].tags[*]", `["a","b","c"]`},
+		{nested, "$.items[1].tags[0]", `["b"]`},
+	}
+	for _, tt := range tests {
+		got, err := Query(tt.data, tt.path)
+		if err != nil {
+			t.Errorf("Query(%q): %v", tt.path, err)
+			continue
+		}
+		if got == nil {
+			t.Errorf("Query(%q) returned a nil slice", tt.path)
+		}
+		if s := mustMarshal(t, got); s != tt.want {
+			t.Errorf("Query(%q) = %s, want %s", tt.path, s, tt.want)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"districts", "must start with '$'"},
+		{"$.", "empty member name"},
+		{"$..a", "empty member name"},
+		{"$[0", "missing ']'"},
+		{"$[-1]", `invalid index "-1"`},
+		{"$[a]", `invalid index "a"`},
+		{"$x", `unexpected 'x'`},
+	}
+	for _, tt := range tests {
+		_, err := Query(nil, tt.path)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Query(%q) error = %v, want %q", tt.path, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+// RawMessage is the undecoded source text of a JSON value. It can be parsed
+// later with Parse(string(m)).
+type RawMessage []byte
+
+func (p *Parser) parseRaw() (interface{}, error) {
+	start := p.token.Offset
+	p.lexer.mark, p.lexer.marked = start, true
+	defer func() { p.lexer.marked = false }()
+
+	if err := p.SkipValue(); err != nil {
+		return nil, err
+	}
+	return RawMessage(p.lexer.slice(start, p.prevEnd)), nil
+}
+
+// SkipValue consumes the current value, checking its syntax without building
+// any containers or converting numbers.
+func (p *Parser) SkipValue() error {
+	discard := p.discard
+	p.discard = true
+	var err error
+	if p.opts.Iterative {
+		_, err = p.parseIterative()
+	} else {
+		_, err = p.parseValue()
+	}
+	p.discard = discard
+	return err
+}
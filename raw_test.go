@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRawDepth(t *testing.T) {
+	input := `{"id": 7, "body": { "a" : [1, 2.50, "x\"y"] }, "list": [ true,null ]}`
+	tests := []struct {
+		depth int
+		key   string
+		want  string
+	}{
+		{1, "id", `7`},
+		{1, "body", `{ "a" : [1, 2.50, "x\"y"] }`},
+		{1, "list", `[ true,null ]`},
+	}
+	v, err := ParseWithOptions(input, Options{RawDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := v.(map[string]interface{})
+	for _, tt := range tests {
+		raw, ok := obj[tt.key].(RawMessage)
+		if !ok {
+			t.Errorf("%s = %T, want RawMessage", tt.key, obj[tt.key])
+			continue
+		}
+		if string(raw) != tt.want || !strings.Contains(input, string(raw)) {
+			t.Errorf("%s = %q, want %q", tt.key, raw, tt.want)
+		}
+	}
+
+	v, err = ParseWithOptions(input, Options{RawDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := v.(map[string]interface{})["body"].(map[string]interface{})
+	if raw := string(body["a"].(RawMessage)); raw != `[1, 2.50, "x\"y"]` {
+		t.Errorf("body.a = %q", raw)
+	}
+	reparsed, err := Parse(string(body["a"].(RawMessage)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Marshal(reparsed); got != `[1,2.5,"x\"y"]` {
+		t.Errorf("reparsed raw value = %s", got)
+	}
+}
+
+func TestRawDepthFromReader(t *testing.T) {
+	// The raw span must survive the reader discarding consumed input.
+	long := strings.Repeat("x", 3*readChunkSize)
+	input := `[{"s":"` + long + `"},2]`
+	parser, err := NewParserWithOptions(NewLexerReader(strings.NewReader(input)), Options{RawDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := parser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := string(v.([]interface{})[0].(RawMessage)); raw != `{"s":"`+long+`"}` {
+		t.Errorf("raw value of %d bytes, want %d", len(raw), len(long)+8)
+	}
+}
+
+func TestSkipValue(t *testing.T) {
+	for _, input := range []string{`{"a":[1,{"b":null}]}`, `"s"`, `[]`} {
+		parser, err := NewParser(NewLexer(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parser.SkipValue(); err != nil {
+			t.Errorf("SkipValue(%s): %v", input, err)
+		}
+	}
+	for _, input := range []string{`{"a":[1,}`, `[01]`, `{"a" 1}`} {
+		parser, err := NewParser(NewLexer(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parser.SkipValue(); err == nil {
+			t.Errorf("SkipValue(%s) succeeded", input)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import "fmt"
+
+// ParseErrors is every syntax error found in a document parsed with
+// Options.CollectAllErrors, in the order they were found.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no parse errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+func (p *Parser) parseCollectingErrors() (interface{}, error) {
+	// Stop after the top-level value so that an error in what follows it
+	// does not lose the value.
+	p.valueOnly = true
+	value, err := p.parseJSON()
+	p.valueOnly = false
+	if err == nil && !p.abandoned {
+		err = p.nextToken()
+		if err == nil && p.token.Type != TokenEOF {
+			err = p.errorf("unexpected trailing content after top-level value")
+		}
+		if err == nil {
+			p.keepEndComments(value)
+		}
+	}
+	if err != nil {
+		perr, ok := err.(*ParseError)
+		if !ok {
+			return nil, err
+		}
+		p.errs = append(p.errs, *perr)
+	}
+	if len(p.errs) > 0 {
+		return value, ParseErrors(p.errs)
+	}
+	return value, nil
+}
+
+// resync records err when collecting errors and skips ahead to where the
+// enclosing container, which ends with closer, can carry on: either past a
+// ',' at its own level or at its closing token. depth and pathLen restore
+// the parser to the container's level. Errors other than ParseErrors end the
+// parse. Running out of input sets p.abandoned instead.
+func (p *Parser) resync(err error, depth, pathLen int, closer TokenType) error {
+	perr, ok := err.(*ParseError)
+	if !ok || !p.opts.CollectAllErrors {
+		return err
+	}
+	p.errs = append(p.errs, *perr)
+	p.depth, p.path = depth, p.path[:pathLen]
+
+	nesting := 0
+	for {
+		if p.lexFailed {
+			if p.lexer.err != nil {
+				p.abandoned = true
+				return nil
+			}
+			if p.lexer.offset == p.lexer.start && !p.lexer.atEOF() {
+				p.lexer.advance()
+			}
+			if err := p.skipToken(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch p.token.Type {
+		case TokenEOF:
+			p.abandoned = true
+			return nil
+		case TokenLeftBrace, TokenLeftBracket:
+			nesting++
+		case TokenRightBrace, TokenRightBracket:
+			if nesting > 0 {
+				nesting--
+			} else if p.token.Type == closer {
+				return nil
+			}
+		case TokenComma:
+			if nesting == 0 {
+				if closer == TokenRightBracket {
+					p.path[len(p.path)-1].index++
+				}
+				if err := p.skipToken(); err != nil {
+					return err
+				}
+				if !p.lexFailed {
+					return nil
+				}
+				continue
+			}
+		}
+		if err := p.skipToken(); err != nil {
+			return err
+		}
+	}
+}
+
+// skipToken moves to the next token during recovery, recording rather than
+// returning any syntax error the lexer reports.
+func (p *Parser) skipToken() error {
+	err := p.nextToken()
+	if perr, ok := err.(*ParseError); ok {
+		p.errs = append(p.errs, *perr)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+package main
+
+import "testing"
+
+type errorPos struct {
+	line, col int
+	path      string
+}
+
+func TestCollectAllErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value string
+		errs  []errorPos
+	}{
+		{
+			name:  "two separate errors",
+			input: "{\n  \"a\": tru,\n  \"b\": [1, 2 3],\n  \"c\": 4\n}",
+			value: `{"b":[1,2],"c":4}`,
+			errs:  []errorPos{{2, 8, "$.a"}, {3, 14, "$.b[1]"}},
+		},
+		{
+			name:  "empty array element",
+			input: `[1,,2]`,
+			value: `[1,2]`,
+			errs:  []errorPos{{1, 4, "$[1]"}},
+		},
+		{
+			name:  "missing colon",
+			input: `{"a" 1, "b": 2}`,
+			value: `{"b":2}`,
+			errs:  []errorPos{{1, 6, "$.a"}},
+		},
+		{
+			name:  "truncated array",
+			input: `[1, 2`,
+			value: `[1,2]`,
+			errs:  []errorPos{{1, 6, "$[1]"}},
+		},
+		{
+			name:  "truncated nested",
+			input: `{"a":1,"b":{"c":[2`,
+			value: `{"a":1,"b":{"c":[2]}}`,
+			errs:  []errorPos{{1, 19, "$.b.c[0]"}},
+		},
+		{
+			name:  "bad first element",
+			input: `[tru, 1 2]`,
+			value: `[1]`,
+			errs:  []errorPos{{1, 2, "$[0]"}, {1, 9, "$[1]"}},
+		},
+		{
+			name:  "bad first element of nested array",
+			input: `{"x":[@, {"y":1}], "z":2}`,
+			value: `{"x":[{"y":1}],"z":2}`,
+			errs:  []errorPos{{1, 7, "$.x[0]"}},
+		},
+		{
+			name:  "bad first key",
+			input: "{'a': 1, \"b\": 2}",
+			value: `{"b":2}`,
+			errs:  []errorPos{{1, 2, ""}, {1, 3, ""}, {1, 4, ""}},
+		},
+		{
+			name:  "empty truncated array",
+			input: `[`,
+			value: `[]`,
+			errs:  []errorPos{{1, 2, "$[0]"}},
+		},
+		{
+			name:  "trailing content",
+			input: `[1] x`,
+			value: `[1]`,
+			errs:  []errorPos{{1, 5, ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseWithOptions(tt.input, Options{CollectAllErrors: true})
+			if got, _ := Marshal(v); got != tt.value {
+				t.Errorf("value = %s, want %s", got, tt.value)
+			}
+			errs, ok := err.(ParseErrors)
+			if !ok {
+				t.Fatalf("error = %v, want ParseErrors", err)
+			}
+			if len(errs) != len(tt.errs) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.errs), errs)
+			}
+			for i, want := range tt.errs {
+				got := errorPos{errs[i].Line, errs[i].Col, errs[i].Path}
+				if got != want {
+					t.Errorf("error %d at %+v, want %+v: %s", i, got, want, errs[i].Msg)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectAllErrorsValidInput(t *testing.T) {
+	v, err := ParseWithOptions(`{"a":[1,2]}`, Options{CollectAllErrors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Marshal(v); got != `{"a":[1,2]}` {
+		t.Errorf("value = %s", got)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "strconv"
+
+type RedactOptions struct {
+	// Strict makes a pointer that does not resolve an error instead of being
+	// skipped.
+	Strict bool
+}
+
+// Redact returns a copy of data with the value at each JSON Pointer replaced
+// by replacement. Only the objects and arrays along each pointer are copied,
+// so data itself is never modified. Pointers that do not resolve are skipped.
+func Redact(data interface{}, pointers []string, replacement interface{}) (interface{}, error) {
+	return RedactWithOptions(data, pointers, replacement, RedactOptions{})
+}
+
+func RedactWithOptions(data interface{}, pointers []string, replacement interface{}, opts RedactOptions) (interface{}, error) {
+	for _, pointer := range pointers {
+		tokens, err := splitPointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := GetPointer(data, pointer); err != nil {
+			if opts.Strict {
+				return nil, err
+			}
+			continue
+		}
+		data = replaceAt(data, tokens, replacement)
+	}
+	return data, nil
+}
+
+// replaceAt returns node with the value at tokens, which must exist, set to
+// replacement, copying each container on the way down.
+func replaceAt(node interface{}, tokens []string, replacement interface{}) interface{} {
+	if len(tokens) == 0 {
+		return replacement
+	}
+	token, rest := tokens[0], tokens[1:]
+	switch v := node.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			copied[key] = value
+		}
+		copied[token] = replaceAt(v[token], rest, replacement)
+		return copied
+	case *OrderedMap:
+		copied := v.shallowCopy()
+		child, _ := v.Get(token)
+		copied.Set(token, replaceAt(child, rest, replacement))
+		return copied
+	case []interface{}:
+		index, _ := strconv.Atoi(token)
+		copied := make([]interface{}, len(v))
+		copy(copied, v)
+		copied[index] = replaceAt(v[index], rest, replacement)
+		return copied
+	}
+	return node
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	input := `{"user":{"name":"asha","password":"hunter2"},"tokens":["a","b","c"],"id":7}`
+	tests := []struct {
+		pointers []string
+		want     string
+	}{
+		{[]string{"/user/password"}, `{"id":7,"tokens":["a","b","c"],"user":{"name":"asha","password":"***"}}`},
+		{[]string{"/tokens/1"}, `{"id":7,"tokens":["a","***","c"],"user":{"name":"asha","password":"hunter2"}}`},
+		{[]string{"/user/password", "/tokens/0", "/missing", "/tokens/9"}, `{"id":7,"tokens":["***","b","c"],"user":{"name":"asha","password":"***"}}`},
+		{[]string{"/user"}, `{"id":7,"tokens":["a","b","c"],"user":"***"}`},
+		{[]string{""}, `"***"`},
+		{nil, `{"id":7,"tokens":["a","b","c"],"user":{"name":"asha","password":"hunter2"}}`},
+	}
+	for _, tt := range tests {
+		data := mustParse(t, input)
+		redacted, err := Redact(data, tt.pointers, "***")
+		if err != nil {
+			t.Errorf("Redact(%q): %v", tt.pointers, err)
+			continue
+		}
+		if got := mustMarshal(t, redacted); got != tt.want {
+			t.Errorf("Redact(%q) = %s, want %s", tt.pointers, got, tt.want)
+		}
+		if !Equal(data, mustParse(t, input)) {
+			t.Errorf("Redact(%q) modified its input", tt.pointers)
+		}
+	}
+}
+
+func TestRedactOrdered(t *testing.T) {
+	data, _ := ParseWithOptions(`{"b":{"secret":1,"x":2},"a":3}`, Options{OrderedObjects: true})
+	redacted, err := Redact(data, []string{"/b/secret"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(t, redacted); got != `{"b":{"secret":null,"x":2},"a":3}` {
+		t.Errorf("Redact = %s", got)
+	}
+	if got := mustMarshal(t, data); got != `{"b":{"secret":1,"x":2},"a":3}` {
+		t.Errorf("Redact modified its input to %s", got)
+	}
+}
+
+func TestRedactStrict(t *testing.T) {
+	data := mustParse(t, `{"a":[1]}`)
+	strict := RedactOptions{Strict: true}
+	for _, pointer := range []string{"/b", "/a/1", "/a/0/x"} {
+		_, err := RedactWithOptions(data, []string{pointer}, "***", strict)
+		if err == nil || !strings.Contains(err.Error(), "json pointer") {
+			t.Errorf("strict Redact(%q) error = %v", pointer, err)
+		}
+	}
+	if _, err := Redact(data, []string{"a"}, "***"); err == nil {
+		t.Error("Redact accepted a malformed pointer")
+	}
+}
+
+func TestRedactKeepsComments(t *testing.T) {
+	v, err := ParseWithOptions(jsoncSample, Options{PreserveComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted, err := Redact(v, []string{"/files/0"}, "***")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MarshalWithOptions(redacted, MarshalOptions{Indent: "  ", EmitComments: true})
+	if want := strings.Replace(jsoncOutput, `"a.ts"`, `"***"`, 1); err != nil || got != want {
+		t.Errorf("redacted JSONC = %s, %v; want\n%s", got, err, want)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateShape checks that data has a value of the expected JSON type at
+// each entry of shape. Keys are JSON Pointers such as "/address/continent",
+// or plain member names of the root object; types are "object", "array",
+// "string", "number", "boolean" or "null". Entries are checked in sorted key
+// order and the first missing or mismatched value is reported.
+func ValidateShape(data interface{}, shape map[string]string) error {
+	keys := make([]string, 0, len(shape))
+	for key := range shape {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		want := shape[key]
+		switch want {
+		case "object", "array", "string", "number", "boolean", "null":
+		default:
+			return fmt.Errorf("shape %q: unknown type %q", key, want)
+		}
+		pointer := key
+		if !strings.HasPrefix(key, "/") {
+			pointer = "/" + pointerEscaper.Replace(key)
+		}
+		value, err := GetPointer(data, pointer)
+		if err != nil {
+			return fmt.Errorf("shape mismatch at %s: expected %s, value is missing", pointer, want)
+		}
+		if got := jsonTypeName(value); got != want {
+			return fmt.Errorf("shape mismatch at %s: expected %s, found %s", pointer, want, got)
+		}
+	}
+	return nil
+}
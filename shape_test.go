@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestValidateShape(t *testing.T) {
+	data := mustParse(t, sampleDocument)
+	conforming := map[string]string{
+		"name":               "string",
+		"age":                "number",
+		"country":            "boolean",
+		"districts":          "array",
+		"/districts/0":       "string",
+		"address":            "object",
+		"/address/continent": "string",
+		"a/b~c":              "number",
+	}
+	if err := ValidateShape(data, conforming); err != nil {
+		t.Errorf("ValidateShape: %v", err)
+	}
+	if err := ValidateShape(mustParse(t, `{"a":null}`), map[string]string{"a": "null"}); err != nil {
+		t.Errorf("ValidateShape with null: %v", err)
+	}
+
+	tests := []struct {
+		shape map[string]string
+		want  string
+	}{
+		{map[string]string{"age": "string"}, "shape mismatch at /age: expected string, found number"},
+		{map[string]string{"/address/continent": "array", "name": "number"}, "shape mismatch at /address/continent: expected array, found string"},
+		{map[string]string{"zip": "number"}, "shape mismatch at /zip: expected number, value is missing"},
+		{map[string]string{"age": "integer"}, `shape "age": unknown type "integer"`},
+	}
+	for _, tt := range tests {
+		err := ValidateShape(data, tt.shape)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ValidateShape(%v) = %v, want %s", tt.shape, err, tt.want)
+		}
+	}
+}
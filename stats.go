@@ -0,0 +1,43 @@
+package main
+
+// Stats summarizes the shape of a parsed document.
+type Stats struct {
+	MaxDepth   int
+	NumTokens  int
+	NumObjects int
+	NumArrays  int
+	NumStrings int
+	NumNumbers int
+	NumBools   int
+	NumNulls   int
+}
+
+func (s *Stats) count(t TokenType) {
+	switch t {
+	case TokenString:
+		s.NumStrings++
+	case TokenNumber:
+		s.NumNumbers++
+	case TokenBoolean:
+		s.NumBools++
+	case TokenNull:
+		s.NumNulls++
+	}
+}
+
+// ParseStats parses input like Parse and also reports how many values of
+// each kind it contains and how deeply they nest.
+func ParseStats(input string) (interface{}, Stats, error) {
+	var stats Stats
+	parser, err := NewParser(NewLexer(input))
+	if err != nil {
+		return nil, stats, err
+	}
+	parser.stats = &stats
+	value, err := parser.parseDocument()
+	if err != nil {
+		return nil, stats, err
+	}
+	stats.NumTokens = parser.lexer.tokens
+	return value, stats, nil
+}
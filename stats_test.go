@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestParseStats(t *testing.T) {
+	tokens, err := Tokens(sampleDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		want  Stats
+	}{
+		{sampleDocument, Stats{MaxDepth: 2, NumTokens: len(tokens) - 1, NumObjects: 2, NumArrays: 1, NumStrings: 5, NumNumbers: 2, NumBools: 1}},
+		{`[[[]],{"a":[null,false,"s"]},1.5]`, Stats{MaxDepth: 3, NumTokens: 20, NumObjects: 1, NumArrays: 4, NumStrings: 1, NumNumbers: 1, NumBools: 1, NumNulls: 1}},
+		{`"s"`, Stats{NumTokens: 1, NumStrings: 1}},
+	}
+	for _, tt := range tests {
+		v, stats, err := ParseStats(tt.input)
+		if err != nil {
+			t.Errorf("ParseStats(%s): %v", tt.input, err)
+			continue
+		}
+		if stats != tt.want {
+			t.Errorf("ParseStats(%s) = %+v, want %+v", tt.input, stats, tt.want)
+		}
+		if !Equal(v, mustParse(t, tt.input)) {
+			t.Errorf("ParseStats(%s) value = %v", tt.input, v)
+		}
+	}
+	if _, _, err := ParseStats(`[1,`); err == nil {
+		t.Error("ParseStats accepted invalid input")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// TypedValue is a scalar as returned by the parser when Options.TagTypes is
+// set. JSONType is "string", "number", "boolean" or "null", so the string
+// "true" can be told apart from the boolean true even after conversion.
+type TypedValue struct {
+	JSONType string
+	Value    interface{}
+}
+
+var scalarTypeNames = map[TokenType]string{
+	TokenString:  "string",
+	TokenNumber:  "number",
+	TokenBoolean: "boolean",
+	TokenNull:    "null",
+}
+
+// TypeOf returns the JSON type of a parsed value: "object", "array",
+// "string", "number", "boolean" or "null". Every number representation the
+// parser produces is a "number", a TypedValue reports its JSONType and a
+// RawMessage is typed by its first character. It returns "" for values the
+// parser never produces and for an empty RawMessage.
+func TypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}, *OrderedMap:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case int64, float64, Number, Decimal, *big.Int:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	case TypedValue:
+		return v.JSONType
+	case RawMessage:
+		return rawTypeOf(v)
+	}
+	return ""
+}
+
+// rawTypeOf looks only at the first character of m, which the parser has
+// already checked. NaN, Infinity and the JSON5 number forms are numbers.
+func rawTypeOf(m RawMessage) string {
+	m = bytes.TrimLeft(m, " \t\r\n")
+	if len(m) == 0 {
+		return ""
+	}
+	switch m[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"', '\'':
+		return "string"
+	case 't', 'T', 'f', 'F':
+		return "boolean"
+	case 'n', 'N':
+		if len(m) > 1 && (m[1] == 'u' || m[1] == 'U') {
+			return "null"
+		}
+	}
+	return "number"
+}
@@ -0,0 +1,118 @@
+package main
+
+import "testing"
+
+func TestTagTypes(t *testing.T) {
+	input := `[true, "true", 1, "1", null, "null", {"k": [false]}]`
+	v, err := ParseWithOptions(input, Options{TagTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := v.([]interface{})
+	want := []TypedValue{
+		{"boolean", true},
+		{"string", "true"},
+		{"number", int64(1)},
+		{"string", "1"},
+		{"null", nil},
+		{"string", "null"},
+	}
+	for i, w := range want {
+		if arr[i] != w {
+			t.Errorf("element %d = %#v, want %#v", i, arr[i], w)
+		}
+	}
+	// Containers are left as they are; only the scalars inside are tagged.
+	inner := arr[6].(map[string]interface{})["k"].([]interface{})
+	if inner[0] != (TypedValue{"boolean", false}) {
+		t.Errorf("nested element = %#v", inner[0])
+	}
+	if got := mustMarshal(t, v); got != `[true,"true",1,"1",null,"null",{"k":[false]}]` {
+		t.Errorf("Marshal of tagged values = %s", got)
+	}
+	v, err = ParseWithOptions(`1.5`, Options{TagTypes: true, UseNumber: true})
+	if err != nil || v != (TypedValue{"number", Number("1.5")}) {
+		t.Errorf("tagged Number = %#v, %v", v, err)
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{map[string]interface{}{}, "object"},
+		{NewOrderedMap(), "object"},
+		{[]interface{}{}, "array"},
+		{"s", "string"},
+		{int64(1), "number"},
+		{1.5, "number"},
+		{Number("1"), "number"},
+		{Decimal("1"), "number"},
+		{true, "boolean"},
+		{nil, "null"},
+		{TypedValue{"string", "x"}, "string"},
+		{RawMessage(`{"a":1}`), "object"},
+		{RawMessage(" [1]"), "array"},
+		{RawMessage(`"x"`), "string"},
+		{RawMessage(`'x'`), "string"},
+		{RawMessage(`-1.5`), "number"},
+		{RawMessage(`NaN`), "number"},
+		{RawMessage(`Infinity`), "number"},
+		{RawMessage(`false`), "boolean"},
+		{RawMessage(`null`), "null"},
+		{RawMessage(`NULL`), "null"},
+		{RawMessage(nil), ""},
+		{struct{}{}, ""},
+		{1, ""},
+	}
+	for _, tt := range tests {
+		if got := TypeOf(tt.value); got != tt.want {
+			t.Errorf("TypeOf(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTypeOfParsedValues(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		want  string
+	}{
+		{`{}`, Options{}, "object"},
+		{`{}`, Options{OrderedObjects: true}, "object"},
+		{`[]`, Options{}, "array"},
+		{`""`, Options{}, "string"},
+		{`1`, Options{}, "number"},
+		{`1.5`, Options{}, "number"},
+		{`1e400`, Options{UseNumber: true}, "number"},
+		{`0.1`, Options{UseDecimalStrings: true}, "number"},
+		{`99999999999999999999`, Options{BigIntFallback: true}, "number"},
+		{`false`, Options{}, "boolean"},
+		{`null`, Options{}, "null"},
+		{`null`, Options{TagTypes: true}, "null"},
+		{`"1"`, Options{TagTypes: true}, "string"},
+	}
+	for _, tt := range tests {
+		v, err := ParseWithOptions(tt.input, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := TypeOf(v); got != tt.want {
+			t.Errorf("TypeOf(%s with %+v) = %q, want %q", tt.input, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestTypeOfRawMessages(t *testing.T) {
+	v, err := ParseWithOptions(`[{"a":1}, [2], "s", 3, true, null]`, Options{RawDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"object", "array", "string", "number", "boolean", "null"}
+	for i, elem := range v.([]interface{}) {
+		if got := TypeOf(elem); got != want[i] {
+			t.Errorf("TypeOf(%s) = %q, want %q", elem, got, want[i])
+		}
+	}
+}
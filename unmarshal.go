@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Unmarshal parses input and stores the result in the value pointed to by v.
+// Object keys are matched to struct fields by their `json` tag, falling back
+// to a case-insensitive match on the field name. Integers too large for an
+// int64 still fill unsigned targets exactly, and arrive as float64 in an
+// interface{} as they would from Parse.
+func Unmarshal(input string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("unmarshal target must be a non-nil pointer")
+	}
+	data, err := ParseWithOptions(input, Options{BigIntFallback: true})
+	if err != nil {
+		return err
+	}
+	return assign(rv.Elem(), data)
+}
+
+func assign(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return mismatch(dst, src)
+		}
+		dst.Set(reflect.ValueOf(floatBigInts(src)))
+		return nil
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), src)
+	case reflect.Struct:
+		obj, ok := src.(map[string]interface{})
+		if !ok {
+			return mismatch(dst, src)
+		}
+		return assignStruct(dst, obj)
+	case reflect.Map:
+		obj, ok := src.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch(dst, src)
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(obj)))
+		}
+		for key, value := range obj {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(elem, value); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		arr, ok := src.([]interface{})
+		if !ok {
+			return mismatch(dst, src)
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+		for i, value := range arr {
+			if err := assign(slice.Index(i), value); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Array:
+		arr, ok := src.([]interface{})
+		if !ok || len(arr) > dst.Len() {
+			return mismatch(dst, src)
+		}
+		dst.Set(reflect.Zero(dst.Type()))
+		for i, value := range arr {
+			if err := assign(dst.Index(i), value); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.String:
+		s, ok := src.(string)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		dst.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return mismatch(dst, src)
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt64(src)
+		if !ok || dst.OverflowInt(n) {
+			return mismatch(dst, src)
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toUint64(src)
+		if !ok || dst.OverflowUint(n) {
+			return mismatch(dst, src)
+		}
+		dst.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch n := src.(type) {
+		case int64:
+			f = float64(n)
+		case float64:
+			f = n
+		case *big.Int:
+			f, _ = new(big.Float).SetInt(n).Float64()
+		default:
+			return mismatch(dst, src)
+		}
+		if dst.OverflowFloat(f) {
+			return mismatch(dst, src)
+		}
+		dst.SetFloat(f)
+		return nil
+	}
+	return mismatch(dst, src)
+}
+
+func assignStruct(dst reflect.Value, obj map[string]interface{}) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		value, ok := obj[name]
+		if !ok {
+			for key, v := range obj {
+				if strings.EqualFold(key, name) {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := assign(dst.Field(i), value); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func toInt64(src interface{}) (int64, bool) {
+	switch n := src.(type) {
+	case int64:
+		return n, true
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func toUint64(src interface{}) (uint64, bool) {
+	switch n := src.(type) {
+	case int64:
+		return uint64(n), n >= 0
+	case float64:
+		if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(n), true
+	case *big.Int:
+		return n.Uint64(), n.IsUint64()
+	}
+	return 0, false
+}
+
+// floatBigInts replaces the *big.Int values Unmarshal's parse produces with
+// the float64 that Parse would have returned, so that interface{} targets see
+// the usual representation.
+func floatBigInts(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = floatBigInts(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = floatBigInts(value)
+		}
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f
+	}
+	return v
+}
+
+func mismatch(dst reflect.Value, src interface{}) error {
+	return fmt.Errorf("cannot unmarshal %s into Go value of type %s", jsonTypeName(src), dst.Type())
+}
+
+func jsonTypeName(v interface{}) string {
+	if name := TypeOf(v); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// UnmarshalStringMap parses an object whose values are all strings. The first
+// member, in document order, holding any other type is reported as an error.
+func UnmarshalStringMap(input string) (map[string]string, error) {
+	data, err := ParseWithOptions(input, Options{OrderedObjects: true})
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := data.(*OrderedMap)
+	if !ok {
+		return nil, fmt.Errorf("cannot unmarshal %s into map[string]string", jsonTypeName(data))
+	}
+	result := make(map[string]string, obj.Len())
+	for _, pair := range obj.Pairs() {
+		s, ok := pair.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("key %q: cannot unmarshal %s into string", pair.Key, jsonTypeName(pair.Value))
+		}
+		result[pair.Key] = s
+	}
+	return result, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  *int   `json:"zip"`
+}
+
+type testPerson struct {
+	Name    string      `json:"full_name"`
+	Age     uint8       `json:"age"`
+	Score   float32     `json:"score"`
+	Active  bool        `json:"active"`
+	Tags    []string    `json:"tags"`
+	Address testAddress `json:"address"`
+	Extra   interface{} `json:"extra"`
+	Skipped string      `json:"-"`
+	Nick    string
+	private int
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	input := `{
+		"full_name": "Asha",
+		"age": 30,
+		"score": 9.5,
+		"active": true,
+		"tags": ["a", "b"],
+		"address": {"city": "Pokhara", "zip": 33700},
+		"extra": [1, "x"],
+		"Skipped": "no",
+		"NICK": "ash",
+		"private": 1
+	}`
+	var got testPerson
+	if err := Unmarshal(input, &got); err != nil {
+		t.Fatal(err)
+	}
+	zip := 33700
+	want := testPerson{
+		Name:    "Asha",
+		Age:     30,
+		Score:   9.5,
+		Active:  true,
+		Tags:    []string{"a", "b"},
+		Address: testAddress{City: "Pokhara", Zip: &zip},
+		Extra:   []interface{}{int64(1), "x"},
+		Nick:    "ash",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalValues(t *testing.T) {
+	var ints []int
+	if err := Unmarshal(`[1, 2.0, -3]`, &ints); err != nil || !reflect.DeepEqual(ints, []int{1, 2, -3}) {
+		t.Errorf("[]int = %v, %v", ints, err)
+	}
+	var m map[string][2]bool
+	if err := Unmarshal(`{"x":[true],"y":[false,true]}`, &m); err != nil ||
+		!reflect.DeepEqual(m, map[string][2]bool{"x": {true, false}, "y": {false, true}}) {
+		t.Errorf("map = %v, %v", m, err)
+	}
+	p := new(int)
+	if err := Unmarshal(`null`, &p); err != nil || p != nil {
+		t.Errorf("null into pointer = %v, %v", p, err)
+	}
+}
+
+func TestUnmarshalUint64(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint64
+	}{
+		{`18446744073709551615`, math.MaxUint64},
+		{`9223372036854775808`, 1 << 63},
+		{`42`, 42},
+		{`1e3`, 1000},
+	}
+	for _, tt := range tests {
+		var u uint64
+		if err := Unmarshal(tt.input, &u); err != nil || u != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", tt.input, u, err, tt.want)
+		}
+	}
+	for _, input := range []string{`18446744073709551616`, `-1`, `1.5`} {
+		var u uint64
+		if err := Unmarshal(input, &u); err == nil {
+			t.Errorf("Unmarshal(%s) = %d, want an error", input, u)
+		}
+	}
+
+	var v interface{}
+	if err := Unmarshal(`[18446744073709551615]`, &v); err != nil || !reflect.DeepEqual(v, []interface{}{float64(math.MaxUint64)}) {
+		t.Errorf("interface{} = %#v, %v, want the float64 Parse returns", v, err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var person testPerson
+	var n int8
+	var arr [1]int
+	tests := []struct {
+		input  string
+		target interface{}
+		err    string
+	}{
+		{`{"age": 300}`, &person, "field Age: cannot unmarshal number into Go value of type uint8"},
+		{`{"age": -1}`, &person, "field Age"},
+		{`{"tags": ["a", 1]}`, &person, "field Tags: index 1: cannot unmarshal number into Go value of type string"},
+		{`{"address": "x"}`, &person, "field Address: cannot unmarshal string"},
+		{`1.5`, &n, "cannot unmarshal number into Go value of type int8"},
+		{`[1, 2]`, &arr, "cannot unmarshal array"},
+		{`{`, &n, "parse error"},
+		{`1`, n, "non-nil pointer"},
+		{`1`, (*int)(nil), "non-nil pointer"},
+	}
+	for _, tt := range tests {
+		err := Unmarshal(tt.input, tt.target)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Unmarshal(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalSliceOfStructs(t *testing.T) {
+	var got []struct{ A int }
+	if err := Unmarshal(`[{"a":1},{"a":2}]`, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].A != 1 || got[1].A != 2 {
+		t.Errorf("Unmarshal = %+v", got)
+	}
+
+	type item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	var items []*item
+	if err := Unmarshal(`[{"name":"x","tags":["a"]},null,{"name":"y"}]`, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].Name != "x" || items[1] != nil || items[2].Name != "y" || items[2].Tags != nil {
+		t.Errorf("Unmarshal = %+v", items)
+	}
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`[{"a":1},"x"]`, "index 1: cannot unmarshal string into Go value of type struct { A int }"},
+		{`[{"a":"1"}]`, "index 0: field A: cannot unmarshal string into Go value of type int"},
+		{`[{"a":1.5}]`, "index 0: field A: cannot unmarshal number"},
+		{`{"a":1}`, "cannot unmarshal object into Go value of type []struct { A int }"},
+	}
+	for _, tt := range tests {
+		var v []struct{ A int }
+		err := Unmarshal(tt.input, &v)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Unmarshal(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalStringMap(t *testing.T) {
+	got, err := UnmarshalStringMap(`{"a": "x", "b": "", "c": "é"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": "x", "b": "", "c": "é"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalStringMap = %v, want %v", got, want)
+	}
+	if got, err := UnmarshalStringMap(`{}`); err != nil || len(got) != 0 {
+		t.Errorf("UnmarshalStringMap({}) = %v, %v", got, err)
+	}
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"a": "x", "b": 1, "c": true}`, `key "b": cannot unmarshal number into string`},
+		{`{"z": null, "a": 1}`, `key "z": cannot unmarshal null into string`},
+		{`{"a": {"b": "c"}}`, `key "a": cannot unmarshal object into string`},
+		{`["a"]`, "cannot unmarshal array into map[string]string"},
+		{`"a"`, "cannot unmarshal string into map[string]string"},
+		{`{"a": "x"`, "parse error"},
+	}
+	for _, tt := range tests {
+		_, err := UnmarshalStringMap(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("UnmarshalStringMap(%s) error = %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+// Valid reports whether input is a single well-formed JSON document.
+func Valid(input string) bool {
+	return ValidateError(input) == nil
+}
+
+// ValidateError checks the syntax of input like Parse does, but without
+// building the resulting value, and returns the first error found.
+func ValidateError(input string) error {
+	// Nothing is kept once the check is done, so the lexer need not copy
+	// input.
+	parser, err := NewParser(newStringLexer(input))
+	if err != nil {
+		return err
+	}
+	parser.discard = true
+	_, err = parser.parseDocument()
+	return err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	valid := []string{
+		sampleDocument,
+		`[]`,
+		`{"a":[1,-2.5e3,"x\n",true,false,null,{}]}`,
+		` "s" `,
+	}
+	for _, input := range valid {
+		if err := ValidateError(input); err != nil {
+			t.Errorf("ValidateError(%s): %v", input, err)
+		}
+		if !Valid(input) {
+			t.Errorf("Valid(%s) = false", input)
+		}
+	}
+
+	invalid := []struct {
+		input string
+		err   string
+	}{
+		{``, "empty document"},
+		{`{"a":1`, "missing '}' to close object"},
+		{`[1,2}`, "expected ',' or ']'"},
+		{`[1,]`, "trailing comma"},
+		{`{"a" 1}`, "expected ':'"},
+		{`[01]`, "leading zeros"},
+		{`["a\qb"]`, `invalid escape sequence \q`},
+		{`{} {}`, "unexpected trailing content"},
+		{`{"a":1,"a":2}x`, "invalid literal"},
+	}
+	for _, tt := range invalid {
+		if Valid(tt.input) {
+			t.Errorf("Valid(%s) = true", tt.input)
+		}
+		err := ValidateError(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ValidateError(%s) = %v, want %q", tt.input, err, tt.err)
+		}
+		if _, perr := Parse(tt.input); perr == nil || perr.Error() != err.Error() {
+			t.Errorf("ValidateError(%s) = %v, but Parse gives %v", tt.input, err, perr)
+		}
+	}
+}
+
+func TestValidAllocatesLess(t *testing.T) {
+	input := manyObjects(100)
+	parse := testing.AllocsPerRun(10, func() { Parse(input) })
+	valid := testing.AllocsPerRun(10, func() { Valid(input) })
+	if valid > parse/5 {
+		t.Errorf("Valid allocates %v times, Parse %v; want at most a fifth", valid, parse)
+	}
+}
+
+func BenchmarkValid(b *testing.B) {
+	input := manyObjects(10000)
+	b.Run("Valid", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if !Valid(input) {
+				b.Fatal("invalid input")
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Parse(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Walk visits every node of a parsed document depth-first, calling fn with
+// the node's JSON Pointer path and value before visiting its children. The
+// root has the empty path. Members of a map[string]interface{} are visited in
+// sorted key order. If fn returns an error, Walk stops and returns it.
+func Walk(data interface{}, fn func(path string, value interface{}) error) error {
+	return walk("", data, fn)
+}
+
+func walk(path string, data interface{}, fn func(path string, value interface{}) error) error {
+	if err := fn(path, data); err != nil {
+		return err
+	}
+	switch v := data.(type) {
+	case []interface{}:
+		for i, elem := range v {
+			if err := walk(path+"/"+strconv.Itoa(i), elem, fn); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := walk(path+"/"+pointerEscaper.Replace(key), v[key], fn); err != nil {
+				return err
+			}
+		}
+	case *OrderedMap:
+		for _, pair := range v.Pairs() {
+			if err := walk(path+"/"+pointerEscaper.Replace(pair.Key), pair.Value, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
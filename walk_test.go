@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	var paths []string
+	err := Walk(mustParse(t, sampleDocument), func(path string, value interface{}) error {
+		paths = append(paths, path+"="+TypeOf(value))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"=object",
+		"/a~1b~0c=number",
+		"/address=object",
+		"/address/Location=string",
+		"/address/continent=string",
+		"/age=number",
+		"/country=boolean",
+		"/districts=array",
+		"/districts/0=string",
+		"/districts/1=string",
+		"/name=string",
+	}
+	if got := strings.Join(paths, " "); got != strings.Join(want, " ") {
+		t.Errorf("visited:\n%s\nwant:\n%s", got, strings.Join(want, " "))
+	}
+
+	ordered, _ := ParseWithOptions(`{"b":[1],"a":{}}`, Options{OrderedObjects: true})
+	paths = nil
+	Walk(ordered, func(path string, value interface{}) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if got := strings.Join(paths, " "); got != " /b /b/0 /a" {
+		t.Errorf("visited %q in an OrderedMap", got)
+	}
+}
+
+func TestWalkStopsEarly(t *testing.T) {
+	stop := errors.New("stop")
+	var paths []string
+	err := Walk(mustParse(t, sampleDocument), func(path string, value interface{}) error {
+		paths = append(paths, path)
+		if path == "/address/Location" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Walk = %v, want the callback's error", err)
+	}
+	if got := strings.Join(paths, " "); got != " /a~1b~0c /address /address/Location" {
+		t.Errorf("visited %q before stopping", got)
+	}
+}